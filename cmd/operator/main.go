@@ -1,35 +1,62 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
-	"net/http"
+	"time"
 
 	log "github.com/sirupsen/logrus"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
-	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
 	"github.com/liwang-pivotal/zookeeper-operator/pkg/controller"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/health"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/leader"
 	"github.com/liwang-pivotal/zookeeper-operator/pkg/processor"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/webhook"
 )
 
 var (
 	appVersion = "0.0.1"
 
-	printVersion bool
-	baseImage    string
-	kubeConfigFile  string
-	masterHost   string
+	printVersion   bool
+	baseImage      string
+	kubeConfigFile string
+	masterHost     string
 
 	metricListenAddress string
 	metricListenPath    string
+	enablePprof         bool
 
 	namespace string
 
+	zookeeperAuth string
+
+	manageCRDs      bool
+	resyncPeriod    time.Duration
+	serverSideApply bool
+
+	webhookEnabled bool
+	webhookConfig  webhook.Config
+
+	leaderElect                 bool
+	leaderElectionNamespace     string
+	leaderElectionName          string
+	leaderElectionLockType      string
+	leaderElectionIdentity      string
+	leaderElectionLeaseDuration time.Duration
+	leaderElectionRenewDeadline time.Duration
+	leaderElectionRetryPeriod   time.Duration
+
+	shutdownTimeout time.Duration
+
 	logger = log.WithFields(log.Fields{
 		"package": "main",
 	})
@@ -43,11 +70,42 @@ func init() {
 
 	flag.StringVar(&metricListenAddress, "listen-address", ":9090", "The address to listen on for HTTP requests.")
 	flag.StringVar(&metricListenPath, "metric-path", "/metrics", "Path under which the the prometheus metrics can be found")
+	flag.BoolVar(&enablePprof, "enable-pprof", false, "Serve the Go profiler under /debug/pprof on the listen address")
 	flag.StringVar(&namespace, "namespace", "", "Namespace on which the operator listens to CR, if not set then all Namespaces will be used")
+	flag.StringVar(&zookeeperAuth, "zookeeper-auth", "", "Digest credentials (user:password) the operator authenticates with when managing ZooKeeper ACLs")
+
+	flag.BoolVar(&manageCRDs, "manage-crds", true, "Create and upgrade the CustomResourceDefinitions, disable when they are installed out-of-band")
+	flag.DurationVar(&resyncPeriod, "resync-period", 10*time.Minute, "Interval in which all clusters are synced again, changes of their objects are repaired right away, 0 disables it")
+	flag.BoolVar(&serverSideApply, "server-side-apply", false, "Write the objects of clusters with server-side apply, requires the ServerSideApply feature of the API server")
+
+	flag.BoolVar(&webhookEnabled, "webhook", false, "Serve and register the admission webhooks for ZookeeperClusters and the conversion webhook serving them at v1beta1")
+	flag.StringVar(&webhookConfig.ListenAddress, "webhook-listen-address", ":8443", "The address the admission webhooks are served on with TLS")
+	flag.StringVar(&webhookConfig.ServiceName, "webhook-service-name", "zookeeper-operator-webhook", "Name of the Service that routes to the webhook port of the operator pods")
+	flag.StringVar(&webhookConfig.ServiceNamespace, "webhook-service-namespace", os.Getenv("POD_NAMESPACE"), "Namespace of the webhook Service, defaults to $POD_NAMESPACE or default")
+	flag.StringVar(&webhookConfig.CertificateSecret, "webhook-cert-secret", "zookeeper-operator-webhook-cert", "Secret holding the self-managed webhook serving certificate")
+	flag.StringVar(&webhookConfig.ConfigurationName, "webhook-configuration-name", "zookeeper-operator", "Name of the registered Validating- and MutatingWebhookConfiguration")
+
+	hostname, _ := os.Hostname()
+	flag.BoolVar(&leaderElect, "leader-elect", true, "Elect a leader among the operator replicas, only the leader reconciles")
+	flag.StringVar(&leaderElectionNamespace, "leader-election-namespace", os.Getenv("POD_NAMESPACE"), "Namespace of the leader election lock, defaults to $POD_NAMESPACE or default")
+	flag.StringVar(&leaderElectionName, "leader-election-name", "zookeeper-operator", "Name of the leader election lock")
+	flag.StringVar(&leaderElectionLockType, "leader-election-lock-type", "configmaps", "Kind of the leader election lock, configmaps, endpoints or leases")
+	flag.StringVar(&leaderElectionIdentity, "leader-election-identity", hostname, "Identity of this replica in the leader election")
+	flag.DurationVar(&leaderElectionLeaseDuration, "leader-election-lease-duration", 15*time.Second, "Time replicas wait before taking over the lock of a leader that stopped renewing it")
+	flag.DurationVar(&leaderElectionRenewDeadline, "leader-election-renew-deadline", 10*time.Second, "Time the leader keeps retrying to renew the lock before giving up leadership")
+	flag.DurationVar(&leaderElectionRetryPeriod, "leader-election-retry-period", 2*time.Second, "Time between attempts to acquire or renew the lock")
+
+	flag.DurationVar(&shutdownTimeout, "shutdown-timeout", 30*time.Second, "Time restores, recoveries and backups in flight get to finish on SIGTERM before they are interrupted")
 
 	flag.Parse()
-}
 
+	if leaderElectionNamespace == "" {
+		leaderElectionNamespace = "default"
+	}
+	if webhookConfig.ServiceNamespace == "" {
+		webhookConfig.ServiceNamespace = "default"
+	}
+}
 
 func Main() int {
 	if printVersion {
@@ -63,23 +121,28 @@ func Main() int {
 
 	//Creating osSignals first so we can exit at any time.
 	osSignals := make(chan os.Signal, 2)
-	signal.Notify(osSignals, syscall.SIGINT, syscall.SIGKILL, os.Interrupt)
+	signal.Notify(osSignals, syscall.SIGINT, syscall.SIGTERM)
 
-	controlChannel := make(chan int, 2)
+	// ctx stops the informers and the event loop, the first signal cancels it
+	ctx, cancel := context.WithCancel(context.Background())
+	// the lock is only released once the in-flight work drained, so the next leader doesn't race it
+	electionCtx, stopElection := context.WithCancel(context.Background())
+	// serverCtx stops the webhook server last, other replicas may still need it while draining
+	serverCtx, stopServers := context.WithCancel(context.Background())
+	// closed once the leader election has released the lock
+	released := make(chan struct{})
 
 	go func() {
-		for {
-			select {
-			case sig := <-osSignals:
-				logger.WithFields(log.Fields{"signal": sig}).Info("Got Signal from OS shutting Down: ")
-				controlChannel <- 1
-				os.Exit(1)
-			}
-		}
+		sig := <-osSignals
+		logger.WithFields(log.Fields{"signal": sig}).Info("Got Signal from OS shutting Down: ")
+		cancel()
+		sig = <-osSignals
+		logger.WithFields(log.Fields{"signal": sig}).Warn("Got second Signal from OS, exiting immediately")
+		os.Exit(1)
 	}()
 
 	// Init
-	kube, err := kube.New(kubeConfigFile, masterHost)
+	kubeClient, err := kube.New(kubeConfigFile, masterHost)
 	if err != nil {
 		logger.WithFields(log.Fields{
 			"error":      err,
@@ -88,9 +151,9 @@ func Main() int {
 		}).Fatal("Error initilizing kubernetes client ")
 		return 1
 	}
+	kubeClient.ServerSideApply = serverSideApply
 
-
-	controller, err := controller.New(kubeConfigFile, masterHost, namespace)
+	controller, err := controller.New(kubeConfigFile, masterHost, namespace, resyncPeriod)
 	if err != nil {
 		logger.WithFields(log.Fields{
 			"error":      err,
@@ -100,14 +163,86 @@ func Main() int {
 		return 1
 	}
 
-	controller.CreateCustomResourceDefinition()
+	// every replica serves admission and conversion requests, not just the leader
+	if webhookEnabled {
+		server, err := webhook.New(webhookConfig, *kubeClient)
+		if err != nil {
+			logger.WithField("error", err).Fatal("Error initilizing webhooks")
+			return 1
+		}
+		controller.EnableConversionWebhook(server.ConversionWebhook())
+		go func() {
+			if err := server.Run(serverCtx); err != nil {
+				logger.WithField("error", err).Fatal("Webhook server stopped")
+			}
+		}()
+	}
+
+	processor, _ := processor.New(baseImage, zookeeperAuth, controller, *kubeClient)
+
+	// the processor stops with ctx rather than with the leadership, losing the lock exits the operator
+	run := func(context.Context) {
+		if manageCRDs {
+			for _, create := range []func() (*apiextensionsv1beta1.CustomResourceDefinition, error){
+				controller.CreateCustomResourceDefinition,
+				controller.CreateUserCustomResourceDefinition,
+				controller.CreateBackupCustomResourceDefinition,
+			} {
+				if _, err := create(); err != nil {
+					logger.WithField("error", err).Fatal("Error registering CustomResourceDefinition")
+				}
+			}
+		}
+
+		if err := processor.Run(ctx); err != nil {
+			logger.WithField("error", err).Warn("Processor not started")
+		}
+	}
 
-	processor, err := processor.New(baseImage, *controller, controlChannel, *kube)
-	processor.Run()
+	var elector *leader.Elector
+	if leaderElect {
+		elector, err = leader.New(leader.Config{
+			Namespace:     leaderElectionNamespace,
+			Name:          leaderElectionName,
+			LockType:      leaderElectionLockType,
+			Identity:      leaderElectionIdentity,
+			LeaseDuration: leaderElectionLeaseDuration,
+			RenewDeadline: leaderElectionRenewDeadline,
+			RetryPeriod:   leaderElectionRetryPeriod,
+		}, *kubeClient, kubeClient.Recorder, run)
+		if err != nil {
+			logger.WithField("error", err).Fatal("Error initilizing leader election")
+			return 1
+		}
+		go func() {
+			elector.Run(electionCtx)
+			close(released)
+		}()
+	} else {
+		run(ctx)
+	}
 
-	http.Handle(metricListenPath, promhttp.Handler())
-	//Blocking ListenAndServer, so we dont exit
-	logger.Fatal(http.ListenAndServe(metricListenAddress, nil))
+	handler := health.New(processor, elector, enablePprof)
+	handler.Handle(metricListenPath, promhttp.Handler())
+	server := &http.Server{Addr: metricListenAddress, Handler: handler}
+	go func() {
+		if err := server.ListenAndServe(); err != http.ErrServerClosed {
+			logger.WithField("error", err).Fatal("HTTP server stopped")
+		}
+	}()
+
+	<-ctx.Done()
+	if err := processor.Shutdown(shutdownTimeout); err != nil {
+		logger.WithField("error", err).Warn("Processor didn't drain in time")
+	}
+	stopElection()
+	if leaderElect {
+		<-released
+	}
+	stopServers()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelShutdown()
+	server.Shutdown(shutdownCtx)
 	logger.Info("Exiting now")
 
 	return 0
@@ -115,4 +250,4 @@ func Main() int {
 
 func main() {
 	os.Exit(Main())
-}
\ No newline at end of file
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/zookeeper"
+)
+
+var (
+	logger = log.WithFields(log.Fields{
+		"package": "main",
+	})
+
+	commands = map[string]func(args []string) error{
+		"export":  export,
+		"import":  importDump,
+		"probe":   probe,
+		"install": install,
+		"init":    initMember,
+	}
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: zkutil <command> [flags]\n\ncommands:\n")
+	fmt.Fprintf(os.Stderr, "  export   write the znode tree as line-delimited JSON\n")
+	fmt.Fprintf(os.Stderr, "  import   replay a dump written by export\n")
+	fmt.Fprintf(os.Stderr, "  probe    check the readiness or liveness of a member\n")
+	fmt.Fprintf(os.Stderr, "  install  copy zkutil into a directory\n")
+	fmt.Fprintf(os.Stderr, "  init     wait for the DNS names of the members and write the configuration\n")
+}
+
+func connect(servers, auth string) (*zookeeper.Client, error) {
+	if servers == "" {
+		return nil, fmt.Errorf("-servers is required")
+	}
+	return zookeeper.New(strings.Split(servers, ","), auth)
+}
+
+func export(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	servers := flags.String("servers", "", "Comma separated list of host:port of the source ensemble")
+	auth := flags.String("auth", "", "Digest credentials (user:password) to read protected znodes")
+	root := flags.String("root", "/", "Znode to start exporting from")
+	out := flags.String("out", "-", "File to write the dump to, - for stdout")
+	flags.Parse(args)
+
+	client, err := connect(*servers, *auth)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	count, err := client.Export(*root, w)
+	if err != nil {
+		return err
+	}
+	logger.WithField("znodes", count).Info("Export finished")
+	return nil
+}
+
+func importDump(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	servers := flags.String("servers", "", "Comma separated list of host:port of the target ensemble")
+	auth := flags.String("auth", "", "Digest credentials (user:password) the znodes are created with before their ACLs are set, required")
+	in := flags.String("in", "-", "File to read the dump from, - for stdin")
+	overwrite := flags.Bool("overwrite", false, "Replace data and ACL of znodes that already exist")
+	includeEphemeral := flags.Bool("include-ephemeral", false, "Recreate ephemeral znodes as persistent ones instead of skipping them")
+	flags.Parse(args)
+
+	client, err := connect(*servers, *auth)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var r io.Reader = os.Stdin
+	if *in != "-" {
+		file, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	count, err := client.Import(r, zookeeper.ImportOptions{
+		Overwrite:        *overwrite,
+		IncludeEphemeral: *includeEphemeral,
+	})
+	if err != nil {
+		return err
+	}
+	logger.WithField("znodes", count).Info("Import finished")
+	return nil
+}
+
+// probe exits with 0 if the member is live or ready, see zookeeper.Live and zookeeper.Ready.
+func probe(args []string) error {
+	flags := flag.NewFlagSet("probe", flag.ExitOnError)
+	server := flags.String("server", "localhost:2181", "host:port of the member")
+	check := flags.String("check", "ready", "ready or live")
+	timeout := flags.Duration("timeout", 3*time.Second, "Timeout of the srvr command")
+	flags.Parse(args)
+
+	switch *check {
+	case "live":
+		return zookeeper.Live(*server, *timeout)
+	case "ready":
+		return zookeeper.Ready(*server, *timeout)
+	}
+	return fmt.Errorf("unknown check %q", *check)
+}
+
+// initMember prepares the start of the member running on this host. It waits until its own DNS
+// name from $ZK_ENSEMBLE resolves, the other members start at the same time and ZooKeeper retries
+// connecting to them. It writes myid into the data dir and the configuration rendered by the
+// operator together with the server list into the conf dir.
+func initMember(args []string) error {
+	flags := flag.NewFlagSet("init", flag.ExitOnError)
+	configDir := flags.String("config-dir", "/etc/zookeeper-operator", "Directory of the configuration rendered by the operator")
+	confDir := flags.String("conf-dir", "/opt/zookeeper/conf", "Configuration directory of ZooKeeper to write to")
+	dataDir := flags.String("data-dir", "/var/lib/zookeeper/data", "Data directory of ZooKeeper")
+	timeout := flags.Duration("timeout", 5*time.Minute, "How long to wait for the DNS name")
+	dynamic := flags.Bool("dynamic", false, "Write the servers into a dynamic configuration file")
+	flags.Parse(args)
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	id, err := zookeeper.MyID(hostname)
+	if err != nil {
+		return err
+	}
+	members := strings.Split(os.Getenv("ZK_ENSEMBLE"), ";")
+	if len(members) < id || members[id-1] != hostname {
+		return fmt.Errorf("%s is not member %d of the ensemble %v", hostname, id, members)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	if err := zookeeper.WaitForDNS(ctx, members[id-1:id]); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(*dataDir, 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(*dataDir, "myid"), []byte(fmt.Sprintf("%d\n", id)), 0644); err != nil {
+		return err
+	}
+	for _, name := range []string{"java.env", "log4j.properties"} {
+		content, err := ioutil.ReadFile(filepath.Join(*configDir, name))
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(*confDir, name), content, 0644); err != nil {
+			return err
+		}
+	}
+	config, err := ioutil.ReadFile(filepath.Join(*configDir, "zoo.cfg"))
+	if err != nil {
+		return err
+	}
+	servers := zookeeper.ServerConfig(members, *dynamic)
+	if *dynamic {
+		dynamicFile := filepath.Join(*confDir, "zoo.cfg.dynamic")
+		if err := ioutil.WriteFile(dynamicFile, []byte(servers), 0644); err != nil {
+			return err
+		}
+		servers = "dynamicConfigFile=" + dynamicFile + "\n"
+	}
+	config = append(config, servers...)
+	if err := ioutil.WriteFile(filepath.Join(*confDir, "zoo.cfg"), config, 0644); err != nil {
+		return err
+	}
+	logger.WithFields(log.Fields{
+		"myid":    id,
+		"members": len(members),
+	}).Info("Wrote configuration")
+	return nil
+}
+
+// install copies the running executable into a directory, so that an init container can provide
+// zkutil to the ZooKeeper container through a shared volume.
+func install(args []string) error {
+	flags := flag.NewFlagSet("install", flag.ExitOnError)
+	dir := flags.String("dir", "/opt/zkutil", "Directory to copy zkutil into")
+	flags.Parse(args)
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(executable)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(filepath.Join(*dir, "zkutil"), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func main() {
+	// logs go to stderr so that export can write to stdout
+	log.SetOutput(os.Stderr)
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	command, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	if err := command(os.Args[2:]); err != nil {
+		logger.WithField("error", err).Error(os.Args[1] + " failed")
+		os.Exit(1)
+	}
+}
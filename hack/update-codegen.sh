@@ -0,0 +1,48 @@
+#!/bin/sh
+# Regenerates the deepcopy functions of the API packages and the typed clientset, listers and
+# informers of pivotal.io/v1 in pkg/client. Run from the repository root inside $GOPATH with
+# deepcopy-gen, client-gen, lister-gen and informer-gen of k8s.io/code-generator (kubernetes-1.15)
+# installed.
+set -e
+
+PACKAGE=github.com/liwang-pivotal/zookeeper-operator
+OUTPUT_BASE="$(go env GOPATH)/src"
+HEADER=hack/boilerplate.go.txt
+
+deepcopy-gen \
+  --input-dirs "${PACKAGE}/spec,${PACKAGE}/spec/v1beta1" \
+  --output-file-base zz_generated.deepcopy \
+  --output-base "${OUTPUT_BASE}" \
+  --go-header-file "${HEADER}"
+
+# The client generators expect the types of a version in <group>/<version>. The v1 types live
+# in spec, so they are read through a temporary link and the generated imports point back to spec.
+mkdir -p pkg/apis/pivotal
+ln -s ../../../spec pkg/apis/pivotal/v1
+trap 'rm -f pkg/apis/pivotal/v1 && rmdir pkg/apis/pivotal pkg/apis' EXIT
+APIS="${PACKAGE}/pkg/apis/pivotal/v1"
+
+rm -rf pkg/client
+client-gen \
+  --clientset-name versioned \
+  --input-base "${PACKAGE}/pkg/apis" \
+  --input pivotal/v1 \
+  --output-package "${PACKAGE}/pkg/client/clientset" \
+  --output-base "${OUTPUT_BASE}" \
+  --go-header-file "${HEADER}"
+lister-gen \
+  --input-dirs "${APIS}" \
+  --output-package "${PACKAGE}/pkg/client/listers" \
+  --output-base "${OUTPUT_BASE}" \
+  --go-header-file "${HEADER}"
+informer-gen \
+  --input-dirs "${APIS}" \
+  --versioned-clientset-package "${PACKAGE}/pkg/client/clientset/versioned" \
+  --listers-package "${PACKAGE}/pkg/client/listers" \
+  --output-package "${PACKAGE}/pkg/client/informers" \
+  --output-base "${OUTPUT_BASE}" \
+  --go-header-file "${HEADER}"
+
+find pkg/client -name '*.go' -exec sed -i.orig "s|\"${APIS}\"|\"${PACKAGE}/spec\"|" {} +
+find pkg/client -name '*.go.orig' -exec rm {} +
+gofmt -w pkg/client
@@ -0,0 +1,163 @@
+package backup
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/zookeeper"
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+const (
+	// VolumeDir is where the data volume is mounted inside the k8szk container. Archives
+	// hold paths relative to it.
+	VolumeDir = "/var/lib/zookeeper"
+	// DataDir holds the snapshots and LogDir the transaction logs, the dataDir and the
+	// dataLogDir of zoo.cfg.
+	DataDir   = VolumeDir + "/data"
+	LogDir    = VolumeDir + "/log"
+	container = "k8szk"
+)
+
+// listScript prints the snapshots and transaction logs relative to VolumeDir.
+var listScript = fmt.Sprintf("cd %s && ls -1d data/version-2/* log/version-2/* 2>/dev/null; true", VolumeDir)
+
+var (
+	logger = log.WithFields(log.Fields{
+		"package": "backup",
+	})
+)
+
+// Run copies the latest snapshot and the transaction logs following it from the
+// current leader into the store.
+func Run(cluster spec.ZookeeperCluster, client kube.Kubernetes, store Store) (*spec.BackupRecord, error) {
+	methodLogger := logger.WithFields(log.Fields{
+		"method":    "Run",
+		"cluster":   cluster.ObjectMeta.Name,
+		"namespace": cluster.ObjectMeta.Namespace,
+	})
+
+	ordinal, stats, err := zookeeper.Leader(cluster)
+	if err != nil {
+		return nil, err
+	}
+	pod := fmt.Sprintf("%s-%d", cluster.ObjectMeta.Name, ordinal)
+	namespace := cluster.ObjectMeta.Namespace
+
+	var listing bytes.Buffer
+	err = client.Exec(namespace, pod, container, []string{"sh", "-c", listScript}, nil, &listing)
+	if err != nil {
+		return nil, err
+	}
+	files := SelectFiles(strings.Fields(listing.String()))
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no snapshot found on leader %s/%s", namespace, pod)
+	}
+
+	now := time.Now().UTC()
+	record := &spec.BackupRecord{
+		Name:   fmt.Sprintf("%s-%s.tar.gz", now.Format("20060102T150405Z"), zookeeper.FormatZxid(zookeeper.Zxid(stats))),
+		Leader: pod,
+		Zxid:   zookeeper.FormatZxid(zookeeper.Zxid(stats)),
+		Time:   metav1.NewTime(now),
+	}
+	methodLogger.WithFields(log.Fields{
+		"leader": pod,
+		"files":  files,
+		"backup": record.Name,
+	}).Info("Starting backup")
+
+	reader, writer := io.Pipe()
+	go func() {
+		command := append([]string{"tar", "czf", "-", "-C", VolumeDir}, files...)
+		writer.CloseWithError(client.Exec(namespace, pod, container, command, nil, writer))
+	}()
+
+	record.Size, err = store.Put(record.Name, reader)
+	reader.Close()
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Backup failed")
+		return nil, err
+	}
+
+	methodLogger.WithField("size", record.Size).Info("Backup completed")
+	return record, nil
+}
+
+// Prune deletes the oldest archives so that at most retention remain and returns the names still kept.
+// A retention of 0 keeps every archive.
+func Prune(store Store, retention int32) ([]string, error) {
+	names, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	if retention <= 0 || len(names) <= int(retention) {
+		return names, nil
+	}
+
+	expired := names[:len(names)-int(retention)]
+	for _, name := range expired {
+		err = store.Delete(name)
+		if err != nil {
+			return nil, err
+		}
+		logger.WithFields(log.Fields{"method": "Prune", "backup": name}).Info("Deleted expired backup")
+	}
+	return names[len(expired):], nil
+}
+
+// SelectFiles picks the newest snapshot from a listing of the data and the log dir
+// together with the transaction logs that may contain transactions after it. The
+// paths are relative to VolumeDir and returned as they are.
+func SelectFiles(paths []string) []string {
+	snapshot, snapshotZxid := "", int64(-1)
+	var logs []string
+	for _, name := range paths {
+		switch base := path.Base(name); {
+		case strings.HasPrefix(base, "snapshot."):
+			if zxid, ok := fileZxid(name); ok && zxid > snapshotZxid {
+				snapshot, snapshotZxid = name, zxid
+			}
+		case strings.HasPrefix(base, "log."):
+			if _, ok := fileZxid(name); ok {
+				logs = append(logs, name)
+			}
+		}
+	}
+	if snapshot == "" {
+		return nil
+	}
+
+	sort.Slice(logs, func(i, j int) bool {
+		a, _ := fileZxid(logs[i])
+		b, _ := fileZxid(logs[j])
+		return a < b
+	})
+
+	// a log is named after its first transaction, so the last log starting at or
+	// before the snapshot may still hold newer transactions
+	first := 0
+	for i, name := range logs {
+		if zxid, _ := fileZxid(name); zxid <= snapshotZxid {
+			first = i
+		}
+	}
+
+	return append([]string{snapshot}, logs[first:]...)
+}
+
+func fileZxid(name string) (int64, bool) {
+	i := strings.LastIndex(name, ".")
+	zxid, err := strconv.ParseInt(name[i+1:], 16, 64)
+	return zxid, err == nil
+}
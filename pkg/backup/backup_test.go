@@ -0,0 +1,66 @@
+package backup
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelectFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		listing []string
+		files   []string
+	}{
+		{
+			name:    "no snapshot",
+			listing: []string{"log/version-2/log.100000001"},
+		},
+		{
+			name: "logs in the log dir",
+			listing: []string{
+				"data/version-2/acceptedEpoch",
+				"data/version-2/snapshot.100000005",
+				"data/version-2/snapshot.100000009",
+				"log/version-2/log.100000001",
+				"log/version-2/log.100000007",
+				"log/version-2/log.10000000c",
+			},
+			files: []string{
+				"data/version-2/snapshot.100000009",
+				"log/version-2/log.100000007",
+				"log/version-2/log.10000000c",
+			},
+		},
+		{
+			name: "log starting at the snapshot",
+			listing: []string{
+				"data/version-2/snapshot.100000009",
+				"log/version-2/log.100000001",
+				"log/version-2/log.100000009",
+			},
+			files: []string{
+				"data/version-2/snapshot.100000009",
+				"log/version-2/log.100000009",
+			},
+		},
+		{
+			name: "logs in the data dir",
+			listing: []string{
+				"data/version-2/log.100000001",
+				"data/version-2/snapshot.100000000",
+			},
+			files: []string{
+				"data/version-2/snapshot.100000000",
+				"data/version-2/log.100000001",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			files := SelectFiles(test.listing)
+			if strings.Join(files, "\n") != strings.Join(test.files, "\n") {
+				t.Errorf("got %q, want %q", files, test.files)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package backup
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+type filesystemStore struct {
+	dir string
+}
+
+func newFilesystemStore(storage spec.FilesystemStorage, prefix string) (*filesystemStore, error) {
+	dir := filepath.Join(storage.Path, prefix)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &filesystemStore{dir: dir}, nil
+}
+
+func (s *filesystemStore) Put(name string, r io.Reader) (int64, error) {
+	tmp, err := ioutil.TempFile(s.dir, "."+name)
+	if err != nil {
+		return 0, err
+	}
+	size, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return 0, err
+	}
+	return size, os.Rename(tmp.Name(), filepath.Join(s.dir, name))
+}
+
+func (s *filesystemStore) Get(name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.dir, name))
+}
+
+func (s *filesystemStore) List() ([]string, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, file := range files {
+		if file.IsDir() || file.Name()[0] == '.' {
+			continue
+		}
+		names = append(names, file.Name())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *filesystemStore) Delete(name string) error {
+	err := os.Remove(filepath.Join(s.dir, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/zookeeper"
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+const verifyInterval = 10 * time.Second
+
+// Restore stops the cluster, provisions the data volumes of every member and
+// seeds them with the given archive. The StatefulSet is left scaled down.
+func Restore(ctx context.Context, cluster spec.ZookeeperCluster, client kube.Kubernetes, store Store, archive string) error {
+	methodLogger := logger.WithFields(log.Fields{
+		"method":    "Restore",
+		"cluster":   cluster.ObjectMeta.Name,
+		"namespace": cluster.ObjectMeta.Namespace,
+		"archive":   archive,
+	})
+
+	err := kube.StopCluster(ctx, cluster, client)
+	if err != nil {
+		return err
+	}
+	err = kube.CreateDataVolumes(cluster, client)
+	if err != nil {
+		return err
+	}
+
+	for ordinal := 0; ordinal < int(cluster.Spec.BrokerCount); ordinal++ {
+		reader, err := store.Get(archive)
+		if err != nil {
+			return err
+		}
+		err = kube.SeedDataVolume(ctx, cluster, ordinal, reader, client)
+		reader.Close()
+		if err != nil {
+			methodLogger.WithFields(log.Fields{
+				"error":   err,
+				"ordinal": ordinal,
+			}).Error("Cant seed data volume")
+			return err
+		}
+	}
+
+	methodLogger.Info("Restored all members")
+	return nil
+}
+
+// Latest returns the name of the newest archive in the store.
+func Latest(store Store) (string, error) {
+	names, err := store.List()
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("no backup archives found")
+	}
+	return names[len(names)-1], nil
+}
+
+// ArchiveZxid returns the zxid encoded in the name of an archive written by Run.
+func ArchiveZxid(name string) (int64, bool) {
+	name = strings.TrimSuffix(name, ".tar.gz")
+	i := strings.LastIndex(name, "-0x")
+	if i < 0 {
+		return 0, false
+	}
+	zxid, err := zookeeper.ParseZxid(name[i+1:])
+	return zxid, err == nil
+}
+
+// VerifySeed checks that the seeded data volume of member 0 holds the transactions up to
+// the zxid of the archive. The members must not be running.
+func VerifySeed(ctx context.Context, cluster spec.ZookeeperCluster, zxid int64, client kube.Kubernetes) error {
+	seeded, err := DataZxid(ctx, cluster, 0, client)
+	if err != nil {
+		return err
+	}
+	if seeded < zxid {
+		return fmt.Errorf("seeded data ends at zxid %s, before the backup zxid %s",
+			zookeeper.FormatZxid(seeded), zookeeper.FormatZxid(zxid))
+	}
+	return nil
+}
+
+// VerifyRestore waits until the restored ensemble elected a leader and checks that it
+// loaded data up to the given zxid. A leader starts the epoch after the last one in its
+// data, an ensemble that started empty is still in epoch 1 and its counter is unrelated
+// to the one of the data, so the epochs are compared.
+func VerifyRestore(ctx context.Context, cluster spec.ZookeeperCluster, zxid int64, timeout time.Duration) error {
+	var leaderZxid int64
+	err := kube.Poll(ctx, verifyInterval, timeout, func() (bool, error) {
+		_, stats, err := zookeeper.Leader(cluster)
+		if err != nil {
+			return false, nil
+		}
+		leaderZxid = zookeeper.Zxid(stats)
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("restored cluster has no leader: %v", err)
+	}
+	if leaderZxid>>32 <= zxid>>32 {
+		return fmt.Errorf("leader zxid %s is not in an epoch after the one of zxid %s, the ensemble didn't load the restored data",
+			zookeeper.FormatZxid(leaderZxid), zookeeper.FormatZxid(zxid))
+	}
+	return nil
+}
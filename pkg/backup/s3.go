@@ -0,0 +1,80 @@
+package backup
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/minio/minio-go"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+type s3Store struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+func newS3Store(storage spec.S3Storage, prefix string, credentials map[string][]byte) (*s3Store, error) {
+	accessKey, secretKey := string(credentials["accessKeyID"]), string(credentials["secretAccessKey"])
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("s3 credentials secret %q needs accessKeyID and secretAccessKey", storage.CredentialsSecret)
+	}
+
+	client, err := minio.NewWithRegion(storage.Endpoint, accessKey, secretKey, !storage.Insecure, storage.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(storage.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		err = client.MakeBucket(storage.Bucket, storage.Region)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &s3Store{
+		client: client,
+		bucket: storage.Bucket,
+		prefix: strings.TrimPrefix(path.Join(storage.Prefix, prefix), "/") + "/",
+	}, nil
+}
+
+func (s *s3Store) Put(name string, r io.Reader) (int64, error) {
+	return s.client.PutObject(s.bucket, s.prefix+name, r, -1, minio.PutObjectOptions{
+		ContentType: "application/gzip",
+	})
+}
+
+func (s *s3Store) Get(name string) (io.ReadCloser, error) {
+	return s.client.GetObject(s.bucket, s.prefix+name, minio.GetObjectOptions{})
+}
+
+func (s *s3Store) List() ([]string, error) {
+	done := make(chan struct{})
+	defer close(done)
+
+	var names []string
+	for object := range s.client.ListObjects(s.bucket, s.prefix, false, done) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		if strings.HasSuffix(object.Key, "/") {
+			continue
+		}
+		names = append(names, strings.TrimPrefix(object.Key, s.prefix))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *s3Store) Delete(name string) error {
+	return s.client.RemoveObject(s.bucket, s.prefix+name)
+}
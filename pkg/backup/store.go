@@ -0,0 +1,32 @@
+package backup
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+// Store is a flat list of named backup archives.
+type Store interface {
+	// Put stores the archive read from r and returns its size.
+	Put(name string, r io.Reader) (int64, error)
+	Get(name string) (io.ReadCloser, error)
+	// List returns the names of all archives, oldest first.
+	List() ([]string, error)
+	Delete(name string) error
+}
+
+// NewStore returns the store configured in storage. Archives are kept below prefix,
+// credentials are the data of the S3 credentials secret.
+func NewStore(storage spec.BackupStorage, prefix string, credentials map[string][]byte) (Store, error) {
+	switch {
+	case storage.S3 != nil && storage.Filesystem != nil:
+		return nil, fmt.Errorf("only one of s3 and filesystem storage may be set")
+	case storage.S3 != nil:
+		return newS3Store(*storage.S3, prefix, credentials)
+	case storage.Filesystem != nil:
+		return newFilesystemStore(*storage.Filesystem, prefix)
+	}
+	return nil, fmt.Errorf("no backup storage configured")
+}
@@ -0,0 +1,67 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/zookeeper"
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+// DataZxid returns the highest zxid on the data volume of a stopped member, -1 if it has no
+// data. A log is named after its first transaction, so the newest one is read for its last.
+func DataZxid(ctx context.Context, cluster spec.ZookeeperCluster, ordinal int, client kube.Kubernetes) (int64, error) {
+	var listing bytes.Buffer
+	err := kube.ExecOnDataVolume(ctx, cluster, ordinal, []string{"sh", "-c", listScript}, nil, &listing, client)
+	if err != nil {
+		return 0, err
+	}
+	zxid, newestLog := newestFiles(strings.Fields(listing.String()))
+	if newestLog == "" {
+		return zxid, nil
+	}
+
+	reader, writer := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		command := []string{"cat", VolumeDir + "/" + newestLog}
+		writer.CloseWithError(kube.ExecOnDataVolume(ctx, cluster, ordinal, command, nil, writer, client))
+		close(done)
+	}()
+	logZxid, err := zookeeper.LastLoggedZxid(reader)
+	// the rest of the preallocated log isn't needed, wait until the pod of the volume is gone
+	reader.Close()
+	<-done
+	if err != nil {
+		return 0, fmt.Errorf("%s of %s-%d: %v", newestLog, cluster.ObjectMeta.Name, ordinal, err)
+	}
+	if logZxid > zxid {
+		zxid = logZxid
+	}
+	return zxid, nil
+}
+
+// newestFiles parses the output of listScript and returns the zxid of the newest snapshot, -1
+// without one, and the path of the newest transaction log.
+func newestFiles(paths []string) (int64, string) {
+	snapshotZxid, newestLog, logZxid := int64(-1), "", int64(-1)
+	for _, name := range paths {
+		zxid, err := strconv.ParseInt(name[strings.LastIndex(name, ".")+1:], 16, 64)
+		if err != nil {
+			continue
+		}
+		switch base := path.Base(name); {
+		case strings.HasPrefix(base, "snapshot.") && zxid > snapshotZxid:
+			snapshotZxid = zxid
+		case strings.HasPrefix(base, "log.") && zxid > logZxid:
+			newestLog, logZxid = name, zxid
+		}
+	}
+	return snapshotZxid, newestLog
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"context"
+
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/metrics"
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+	log "github.com/sirupsen/logrus"
+)
+
+// UpdateZookeeperBackup writes the backup, including its state, back to the API server.
+func (c *CustomResourceController) UpdateZookeeperBackup(backup *spec.ZookeeperBackup) (*spec.ZookeeperBackup, error) {
+	return c.Client.PivotalV1().ZookeeperBackups(backup.ObjectMeta.Namespace).Update(backup)
+}
+
+// MonitorZookeeperBackupEvents sends the events of the informer to eventsChannel until ctx is done.
+func (c *CustomResourceController) MonitorZookeeperBackupEvents(ctx context.Context, eventsChannel chan spec.ZookeeperBackupWatchEvent) {
+	methodLogger := logger.WithFields(log.Fields{"method": "MonitorZookeeperBackupEvents"})
+	methodLogger.Info("Starting Monitoring")
+
+	send := func(event spec.ZookeeperBackupWatchEvent) {
+		metrics.BackupQueue.Add()
+		select {
+		case eventsChannel <- event:
+		case <-ctx.Done():
+			// nobody receives anymore, dropping the event lets the informer stop
+		}
+	}
+	informer := c.informerFactory.Pivotal().V1().ZookeeperBackups().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			backup := obj.(*spec.ZookeeperBackup)
+			methodLogger.WithFields(log.Fields{"watchFunction": "ADDED"}).Info(spec.PrintBackup(backup))
+			var event spec.ZookeeperBackupWatchEvent
+			event.Type = "ADDED"
+			event.Object = *backup
+			send(event)
+		},
+
+		UpdateFunc: func(old, new interface{}) {
+			oldBackup := old.(*spec.ZookeeperBackup)
+			newBackup := new.(*spec.ZookeeperBackup)
+			methodLogger.WithFields(log.Fields{
+				"eventType": "UPDATED",
+				"old":       spec.PrintBackup(oldBackup),
+				"new":       spec.PrintBackup(newBackup),
+			}).Info("Recieved Update Event")
+			var event spec.ZookeeperBackupWatchEvent
+			event.Type = "UPDATED"
+			event.Object = *newBackup
+			event.OldObject = *oldBackup
+			send(event)
+		},
+
+		DeleteFunc: func(obj interface{}) {
+			backup, ok := obj.(*spec.ZookeeperBackup)
+			if !ok {
+				tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					return
+				}
+				if backup, ok = tombstone.Obj.(*spec.ZookeeperBackup); !ok {
+					return
+				}
+			}
+			methodLogger.WithFields(log.Fields{"watchFunction": "DELETED"}).Info(spec.PrintBackup(backup))
+			var event spec.ZookeeperBackupWatchEvent
+			event.Type = "DELETED"
+			event.Object = *backup
+			send(event)
+		},
+	})
+
+	c.runInformer(spec.CRDBackupRessourcePlural, informer, ctx.Done())
+
+	go func() {
+		<-ctx.Done()
+		methodLogger.Warn("recieved shutdown signal, stopping informer")
+	}()
+}
+
+// GetZookeeperBackup returns a copy of a ZookeeperBackup from the informer cache.
+func (c *CustomResourceController) GetZookeeperBackup(namespace, name string) (*spec.ZookeeperBackup, error) {
+	backup, err := c.informerFactory.Pivotal().V1().ZookeeperBackups().Lister().ZookeeperBackups(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return backup.DeepCopy(), nil
+}
@@ -1,24 +1,32 @@
 package controller
 
 import (
-	"time"
+	"context"
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 
-	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
-	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/tools/cache"
-	"k8s.io/client-go/rest"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/apimachinery/pkg/util/errors"
-	"k8s.io/apimachinery/pkg/fields"
-	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/serializer"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/client/clientset/versioned"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/client/informers/externalversions"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/metrics"
 	"github.com/liwang-pivotal/zookeeper-operator/spec"
+	"github.com/liwang-pivotal/zookeeper-operator/spec/v1beta1"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -29,10 +37,17 @@ var (
 )
 
 type CustomResourceController struct {
-	ApiExtensionsClient *apiextensionsclient.Clientset
-	DefaultOption       metav1.GetOptions
-	crdClient           *rest.RESTClient
-	namespace           string
+	ApiExtensionsClient apiextensionsclient.Interface
+	// Client is the typed clientset of the pivotal.io resources.
+	Client            versioned.Interface
+	DefaultOption     metav1.GetOptions
+	namespace         string
+	conversionWebhook *apiextensionsv1beta1.WebhookClientConfig
+	informerFactory   externalversions.SharedInformerFactory
+	kubeClient        kubernetes.Interface
+	// kubeInformerFactory informs about the objects the operator creates for the clusters
+	kubeInformerFactory kubeinformers.SharedInformerFactory
+	informers           *informers
 }
 
 func GetClientConfig(kubeconfig string) (*rest.Config, error) {
@@ -42,12 +57,21 @@ func GetClientConfig(kubeconfig string) (*rest.Config, error) {
 	return rest.InClusterConfig()
 }
 
-
-func New(kubeConfigFile, masterHost string, namespace string) (*CustomResourceController, error) {
+// New connects to the API server. Every resyncPeriod all clusters are synced again, 0 disables
+// the periodic resync.
+func New(kubeConfigFile, masterHost string, namespace string, resyncPeriod time.Duration) (*CustomResourceController, error) {
 	methodLogger := logger.WithFields(log.Fields{"method": "New"})
 
 	// Create the client config. Use kubeconfig if given, otherwise assume in-cluster.
 	config, err := GetClientConfig(kubeConfigFile)
+	if err != nil {
+		methodLogger.WithFields(log.Fields{
+			"error":  err,
+			"config": kubeConfigFile,
+		}).Error("could not build Kubernetes client config")
+		return nil, err
+	}
+	config.WrapTransport = metrics.InstrumentTransport
 
 	apiextensionsclientset, err := apiextensionsclient.NewForConfig(config)
 	if err != nil {
@@ -59,58 +83,171 @@ func New(kubeConfigFile, masterHost string, namespace string) (*CustomResourceCo
 		return nil, err
 	}
 
-	crdClient, err := newCRDClient(config)
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		methodLogger.WithFields(log.Fields{
+			"error":  err,
+			"config": config,
+		}).Error("could not init Kubernetes client")
+		return nil, err
+	}
+
+	client, err := versioned.NewForConfig(config)
 	if err != nil {
 		methodLogger.WithFields(log.Fields{
 			"Error":  err,
-			"Client": crdClient,
 			"Config": config,
 		}).Error("Could not initialize CustomResourceDefinition Zookeeper cluster client")
 		return nil, err
 	}
 
-	k := &CustomResourceController{
-		crdClient:           crdClient,
-		ApiExtensionsClient: apiextensionsclientset,
-		namespace:           namespace,
-	}
+	k := newController(apiextensionsclientset, kubeClient, client, namespace, resyncPeriod)
 	methodLogger.Info("Initilized CustomResourceDefinition Zookeeper cluster client")
 
 	return k, nil
 }
 
+// newController builds a controller on top of the given clients, watching namespace or all
+// namespaces if it is empty.
+func newController(apiExtensionsClient apiextensionsclient.Interface, kubeClient kubernetes.Interface, client versioned.Interface, namespace string, resyncPeriod time.Duration) *CustomResourceController {
+	return &CustomResourceController{
+		ApiExtensionsClient: apiExtensionsClient,
+		Client:              client,
+		kubeClient:          kubeClient,
+		namespace:           namespace,
+		informerFactory: externalversions.NewSharedInformerFactoryWithOptions(client, resyncPeriod,
+			externalversions.WithNamespace(namespace)),
+		// the periodic resync of the clusters covers their objects as well
+		kubeInformerFactory: kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, 0,
+			kubeinformers.WithNamespace(namespace),
+			kubeinformers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.LabelSelector = kube.ManagedLabelSelector
+			})),
+		informers: newInformers(),
+	}
+}
+
+// EnableConversionWebhook makes CreateCustomResourceDefinition serve ZookeeperClusters at v1beta1 too,
+// converted from the stored v1 objects by the webhook behind clientConfig.
+func (c *CustomResourceController) EnableConversionWebhook(clientConfig *apiextensionsv1beta1.WebhookClientConfig) {
+	c.conversionWebhook = clientConfig
+}
+
+// CreateCustomResourceDefinition creates the ZookeeperCluster CRD or upgrades an existing one.
 func (c *CustomResourceController) CreateCustomResourceDefinition() (*apiextensionsv1beta1.CustomResourceDefinition, error) {
+	crd := newCustomResourceDefinition(spec.CRDRessourcePlural, reflect.TypeOf(spec.ZookeeperCluster{}).Name(), spec.ZookeeperClusterSpec{})
+	if c.conversionWebhook != nil {
+		// v1 stays the storage version, v1beta1 is converted on the fly
+		crd.Spec.Validation = nil
+		crd.Spec.Versions = []apiextensionsv1beta1.CustomResourceDefinitionVersion{
+			{Name: spec.CRDVersion, Served: true, Storage: true, Schema: newValidation(spec.ZookeeperClusterSpec{})},
+			{Name: v1beta1.Version, Served: true, Schema: newValidation(v1beta1.ZookeeperClusterSpec{})},
+		}
+		crd.Spec.Conversion = &apiextensionsv1beta1.CustomResourceConversion{
+			Strategy:                 apiextensionsv1beta1.WebhookConverter,
+			WebhookClientConfig:      c.conversionWebhook,
+			ConversionReviewVersions: []string{"v1beta1"},
+		}
+	}
+	return c.createCustomResourceDefinition(crd)
+}
 
-	methodLogger := logger.WithFields(log.Fields{"method": "CreateCustomResourceDefinition"})
+// CreateUserCustomResourceDefinition creates the ZookeeperUser CRD or upgrades an existing one.
+func (c *CustomResourceController) CreateUserCustomResourceDefinition() (*apiextensionsv1beta1.CustomResourceDefinition, error) {
+	crd := newCustomResourceDefinition(spec.CRDUserRessourcePlural, reflect.TypeOf(spec.ZookeeperUser{}).Name(), spec.ZookeeperUserSpec{})
+	return c.createCustomResourceDefinition(crd)
+}
+
+// CreateBackupCustomResourceDefinition creates the ZookeeperBackup CRD or upgrades an existing one.
+func (c *CustomResourceController) CreateBackupCustomResourceDefinition() (*apiextensionsv1beta1.CustomResourceDefinition, error) {
+	crd := newCustomResourceDefinition(spec.CRDBackupRessourcePlural, reflect.TypeOf(spec.ZookeeperBackup{}).Name(), spec.ZookeeperBackupSpec{})
+	return c.createCustomResourceDefinition(crd)
+}
 
-	crd := &apiextensionsv1beta1.CustomResourceDefinition{
+func newCustomResourceDefinition(plural, kind string, resourceSpec interface{}) *apiextensionsv1beta1.CustomResourceDefinition {
+	return &apiextensionsv1beta1.CustomResourceDefinition{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: spec.CRDFullName,
+			Name: plural + "." + spec.CRDGroupName,
 		},
 		Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
 			Group:   spec.CRDGroupName,
 			Version: spec.CRDVersion,
 			Scope:   apiextensionsv1beta1.NamespaceScoped,
 			Names: apiextensionsv1beta1.CustomResourceDefinitionNames{
-				Plural: spec.CRDRessourcePlural,
-				Kind:   reflect.TypeOf(spec.ZookeeperCluster{}).Name(),
+				Plural: plural,
+				Kind:   kind,
+				// set the defaults of the API server, so an unchanged CRD compares equal
+				Singular: strings.ToLower(kind),
+				ListKind: kind + "List",
+			},
+			Validation: newValidation(resourceSpec),
+			Versions: []apiextensionsv1beta1.CustomResourceDefinitionVersion{
+				{Name: spec.CRDVersion, Served: true, Storage: true},
+			},
+			Conversion: &apiextensionsv1beta1.CustomResourceConversion{
+				Strategy: apiextensionsv1beta1.NoneConverter,
 			},
 		},
 	}
+}
+
+// createCustomResourceDefinition creates the CRD if it doesn't exist yet and otherwise upgrades names, versions,
+// schema and conversion of the existing one when they differ. A CRD is never deleted, that would delete all its resources.
+func (c *CustomResourceController) createCustomResourceDefinition(crd *apiextensionsv1beta1.CustomResourceDefinition) (*apiextensionsv1beta1.CustomResourceDefinition, error) {
+
+	name := crd.ObjectMeta.Name
+	methodLogger := logger.WithFields(log.Fields{
+		"method": "CreateCustomResourceDefinition",
+		"crd":    name,
+	})
+	crds := c.ApiExtensionsClient.ApiextensionsV1beta1().CustomResourceDefinitions()
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := crds.Get(name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			methodLogger.Info("Creating CRD")
+			_, err = crds.Create(crd)
+			return err
+		}
+		if err != nil {
+			return err
+		}
 
-	_, err := c.ApiExtensionsClient.ApiextensionsV1beta1().CustomResourceDefinitions().Create(crd)
+		if existing.Spec.Scope != crd.Spec.Scope {
+			return fmt.Errorf("CRD %s has scope %s instead of %s, it must be recreated manually", name, existing.Spec.Scope, crd.Spec.Scope)
+		}
+		if existing.Spec.Group == crd.Spec.Group &&
+			existing.Spec.Version == crd.Spec.Version &&
+			reflect.DeepEqual(existing.Spec.Names, crd.Spec.Names) &&
+			reflect.DeepEqual(existing.Spec.Validation, crd.Spec.Validation) &&
+			reflect.DeepEqual(existing.Spec.Versions, crd.Spec.Versions) &&
+			reflect.DeepEqual(existing.Spec.Conversion, crd.Spec.Conversion) {
+			methodLogger.Debug("CRD is up to date")
+			return nil
+		}
+
+		methodLogger.Info("Upgrading CRD")
+		existing.Spec.Group = crd.Spec.Group
+		existing.Spec.Version = crd.Spec.Version
+		existing.Spec.Names = crd.Spec.Names
+		existing.Spec.Validation = crd.Spec.Validation
+		existing.Spec.Versions = crd.Spec.Versions
+		existing.Spec.Conversion = crd.Spec.Conversion
+		_, err = crds.Update(existing)
+		return err
+	})
 	if err != nil {
 		methodLogger.WithFields(log.Fields{
 			"error": err,
 			"crd":   crd,
-		}).Error("Error while creating CRD")
+		}).Error("Error while creating or updating CRD")
 		return nil, err
 	}
 
 	// wait for CRD being established
 	methodLogger.Debug("Created CRD, wating till its established")
 	err = wait.Poll(500*time.Millisecond, 60*time.Second, func() (bool, error) {
-		crd, err = c.ApiExtensionsClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(spec.CRDFullName, metav1.GetOptions{})
+		crd, err = crds.Get(name, metav1.GetOptions{})
 		if err != nil {
 			return false, err
 		}
@@ -122,9 +259,7 @@ func (c *CustomResourceController) CreateCustomResourceDefinition() (*apiextensi
 				}
 			case apiextensionsv1beta1.NamesAccepted:
 				if cond.Status == apiextensionsv1beta1.ConditionFalse {
-					fmt.Printf("Name conflict: %v\n", cond.Reason)
 					methodLogger.WithFields(log.Fields{
-						"error":  err,
 						"crd":    crd,
 						"reason": cond.Reason,
 					}).Error("Naming Conflict with created CRD")
@@ -134,112 +269,97 @@ func (c *CustomResourceController) CreateCustomResourceDefinition() (*apiextensi
 		return false, err
 	})
 	if err != nil {
-		deleteErr := c.ApiExtensionsClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete(spec.CRDFullName, nil)
-		if deleteErr != nil {
-			return nil, errors.NewAggregate([]error{err, deleteErr})
-		}
-		return nil, err
+		return nil, fmt.Errorf("CRD %s is not established: %v", name, err)
 	}
 	return crd, nil
 }
 
-func newCRDClient(config *rest.Config) (*rest.RESTClient, error) {
-
-	var cdrconfig *rest.Config
-	cdrconfig = config
-	configureConfig(cdrconfig)
-
-	crdClient, err := rest.RESTClientFor(cdrconfig)
-	if err != nil {
-		panic(err)
-	}
-
-	return crdClient, nil
-}
-
-func configureConfig(cfg *rest.Config) error {
-	scheme := runtime.NewScheme()
-
-	if err := spec.AddToScheme(scheme); err != nil {
-		return err
-	}
-
-	cfg.GroupVersion = &spec.SchemeGroupVersion
-	cfg.APIPath = "/apis"
-	cfg.ContentType = runtime.ContentTypeJSON
-	cfg.NegotiatedSerializer = serializer.DirectCodecFactory{CodecFactory: serializer.NewCodecFactory(scheme)}
-
-	return nil
-}
-
-
-func (c *CustomResourceController) MonitorZookeeperEvents(eventsChannel chan spec.ZookeeperClusterWatchEvent, signalChannel chan int) {
+// MonitorZookeeperEvents sends the events of the informer to eventsChannel until ctx is done.
+func (c *CustomResourceController) MonitorZookeeperEvents(ctx context.Context, eventsChannel chan spec.ZookeeperClusterWatchEvent) {
 	methodLogger := logger.WithFields(log.Fields{"method": "MonitorZookeeperEvents"})
 	methodLogger.Info("Starting Monitoring")
 
-	stop := make(chan struct{}, 1)
-	source := cache.NewListWatchFromClient(
-		c.crdClient,
-		spec.CRDRessourcePlural,
-		c.namespace,
-		fields.Everything())
-
-	store, controller := cache.NewInformer(
-		source,
-
-		&spec.ZookeeperCluster{},
-
-		// resyncPeriod
-		// Every resyncPeriod, all resources in the cache will retrigger events.
-		// Set to 0 to disable the resync.
-		0,
-
-		cache.ResourceEventHandlerFuncs{
-			AddFunc: func(obj interface{}) {
-				cluster := obj.(*spec.ZookeeperCluster)
-				methodLogger.WithFields(log.Fields{"watchFunction": "ADDED",}).Info(spec.PrintCluster(cluster))
-				var event spec.ZookeeperClusterWatchEvent
-				event.Type = "ADDED"
-				event.Object = *cluster
-				eventsChannel <- event
-			},
+	send := func(event spec.ZookeeperClusterWatchEvent) {
+		metrics.ClusterQueue.Add()
+		select {
+		case eventsChannel <- event:
+		case <-ctx.Done():
+			// nobody receives anymore, dropping the event lets the informer stop
+		}
+	}
+	informer := c.informerFactory.Pivotal().V1().ZookeeperClusters().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			cluster := obj.(*spec.ZookeeperCluster)
+			methodLogger.WithFields(log.Fields{"watchFunction": "ADDED"}).Info(spec.PrintCluster(cluster))
+			var event spec.ZookeeperClusterWatchEvent
+			event.Type = "ADDED"
+			event.Object = *cluster
+			send(event)
+		},
 
-			UpdateFunc: func(old, new interface{}) {
-				oldCluster := old.(*spec.ZookeeperCluster)
-				newCluster := new.(*spec.ZookeeperCluster)
-				methodLogger.WithFields(log.Fields{
-					"eventType": "UPDATED",
-					"old":       spec.PrintCluster(oldCluster),
-					"new":       spec.PrintCluster(newCluster),
-				}).Info("Recieved Update Event")
-				var event spec.ZookeeperClusterWatchEvent
-				//TODO refactor this. use old/new in EventChannel
-				event.Type = "UPDATED"
-				event.Object = *newCluster
-				event.OldObject = *oldCluster
-				eventsChannel <- event
-			},
+		UpdateFunc: func(old, new interface{}) {
+			oldCluster := old.(*spec.ZookeeperCluster)
+			newCluster := new.(*spec.ZookeeperCluster)
+			methodLogger.WithFields(log.Fields{
+				"eventType": "UPDATED",
+				"old":       spec.PrintCluster(oldCluster),
+				"new":       spec.PrintCluster(newCluster),
+			}).Info("Recieved Update Event")
+			var event spec.ZookeeperClusterWatchEvent
+			//TODO refactor this. use old/new in EventChannel
+			event.Type = "UPDATED"
+			if oldCluster.ObjectMeta.ResourceVersion == newCluster.ObjectMeta.ResourceVersion {
+				// periodic resync, the cluster didn't change
+				event.Type = "RESYNC"
+			}
+			event.Object = *newCluster
+			event.OldObject = *oldCluster
+			send(event)
+		},
 
-			DeleteFunc: func(obj interface{}) {
-				cluster := obj.(*spec.ZookeeperCluster)
-				methodLogger.WithFields(log.Fields{"watchFunction": "DELETED",}).Info(spec.PrintCluster(cluster))
-				var event spec.ZookeeperClusterWatchEvent
-				event.Type = "DELETED"
-				event.Object = *cluster
-				eventsChannel <- event
-			},
-		})
+		DeleteFunc: func(obj interface{}) {
+			cluster := obj.(*spec.ZookeeperCluster)
+			methodLogger.WithFields(log.Fields{"watchFunction": "DELETED"}).Info(spec.PrintCluster(cluster))
+			var event spec.ZookeeperClusterWatchEvent
+			event.Type = "DELETED"
+			event.Object = *cluster
+			send(event)
+		},
+	})
 
-	// the controller run starts the event processing loop
-	go controller.Run(stop)
-	methodLogger.Info(store)
+	// the informer run starts the event processing loop
+	c.runInformer(spec.CRDRessourcePlural, informer, ctx.Done())
 
 	go func() {
-		select {
-		case <-signalChannel:
-			methodLogger.Warn("recieved shutdown signal, stopping informer")
-			close(stop)
-		}
+		<-ctx.Done()
+		methodLogger.Warn("recieved shutdown signal, stopping informer")
 	}()
 }
 
+// GetZookeeperCluster returns a copy of a ZookeeperCluster from the informer cache.
+func (c *CustomResourceController) GetZookeeperCluster(namespace, name string) (*spec.ZookeeperCluster, error) {
+	cluster, err := c.informerFactory.Pivotal().V1().ZookeeperClusters().Lister().ZookeeperClusters(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return cluster.DeepCopy(), nil
+}
+
+// ListZookeeperClusters returns copies of all ZookeeperClusters of the watched namespace from the informer cache.
+func (c *CustomResourceController) ListZookeeperClusters() ([]spec.ZookeeperCluster, error) {
+	clusters, err := c.informerFactory.Pivotal().V1().ZookeeperClusters().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	result := make([]spec.ZookeeperCluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		result = append(result, *cluster.DeepCopy())
+	}
+	return result, nil
+}
+
+// UpdateZookeeperCluster writes the cluster, including its state, back to the API server.
+func (c *CustomResourceController) UpdateZookeeperCluster(cluster *spec.ZookeeperCluster) (*spec.ZookeeperCluster, error) {
+	return c.Client.PivotalV1().ZookeeperClusters(cluster.ObjectMeta.Namespace).Update(cluster)
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/client/clientset/versioned/fake"
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+func TestMonitorZookeeperEvents(t *testing.T) {
+	cluster := &spec.ZookeeperCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "zk", Namespace: "test", ResourceVersion: "1"},
+		Spec:       spec.ZookeeperClusterSpec{BrokerCount: 3},
+	}
+	client := fake.NewSimpleClientset(cluster)
+	c := newController(apiextensionsfake.NewSimpleClientset(), kubefake.NewSimpleClientset(), client, "test", 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan spec.ZookeeperClusterWatchEvent, 10)
+	c.MonitorZookeeperEvents(ctx, events)
+	if !cache.WaitForCacheSync(ctx.Done(), c.HasSynced) {
+		t.Fatal("informer didn't sync")
+	}
+	select {
+	case event := <-events:
+		if event.Type != "ADDED" || event.Object.Name != "zk" {
+			t.Errorf("got %s of %s, want ADDED of zk", event.Type, event.Object.Name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event for the existing cluster")
+	}
+
+	cached, err := c.GetZookeeperCluster("test", "zk")
+	if err != nil {
+		t.Fatalf("GetZookeeperCluster: %v", err)
+	}
+	if _, err := c.GetZookeeperCluster("test", "missing"); !apierrors.IsNotFound(err) {
+		t.Errorf("GetZookeeperCluster of a missing cluster returned %v, want NotFound", err)
+	}
+	clusters, err := c.ListZookeeperClusters()
+	if err != nil || len(clusters) != 1 {
+		t.Errorf("ListZookeeperClusters returned %d clusters and %v, want 1", len(clusters), err)
+	}
+
+	cached.Spec.BrokerCount = 5
+	// the fake clientset doesn't count resource versions
+	cached.ObjectMeta.ResourceVersion = "2"
+	if _, err := c.UpdateZookeeperCluster(cached); err != nil {
+		t.Fatalf("UpdateZookeeperCluster: %v", err)
+	}
+	select {
+	case event := <-events:
+		if event.Type != "UPDATED" || event.Object.Spec.BrokerCount != 5 || event.OldObject.Spec.BrokerCount != 3 {
+			t.Errorf("got %s from %d to %d brokers, want UPDATED from 3 to 5",
+				event.Type, event.OldObject.Spec.BrokerCount, event.Object.Spec.BrokerCount)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event for the updated cluster")
+	}
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+// informers tracks the informers started by the Monitor functions for the health endpoints.
+// It is shared by all copies of a CustomResourceController.
+type informers struct {
+	mutex       sync.Mutex
+	controllers map[string]cache.Controller
+	stopped     map[string]bool
+}
+
+func newInformers() *informers {
+	return &informers{
+		controllers: make(map[string]cache.Controller),
+		stopped:     make(map[string]bool),
+	}
+}
+
+// runInformer runs the informer until stop is closed and records when it returns.
+func (c *CustomResourceController) runInformer(name string, controller cache.Controller, stop <-chan struct{}) {
+	c.informers.mutex.Lock()
+	c.informers.controllers[name] = controller
+	delete(c.informers.stopped, name)
+	c.informers.mutex.Unlock()
+
+	go func() {
+		controller.Run(stop)
+		c.informers.mutex.Lock()
+		c.informers.stopped[name] = true
+		c.informers.mutex.Unlock()
+	}()
+}
+
+// HasSynced reports whether all started informers have listed their resources once.
+func (c *CustomResourceController) HasSynced() bool {
+	c.informers.mutex.Lock()
+	defer c.informers.mutex.Unlock()
+	if len(c.informers.controllers) == 0 {
+		return false
+	}
+	for _, controller := range c.informers.controllers {
+		if !controller.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// InformersRunning returns an error naming the informers that stopped watching.
+func (c *CustomResourceController) InformersRunning() error {
+	c.informers.mutex.Lock()
+	defer c.informers.mutex.Unlock()
+	var stopped []string
+	for name := range c.informers.stopped {
+		stopped = append(stopped, name)
+	}
+	if len(stopped) > 0 {
+		sort.Strings(stopped)
+		return fmt.Errorf("informers stopped: %v", stopped)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+// Interface is the access to the custom resources of the operator the processor needs.
+// CustomResourceController implements it against the API server, tests pass a fake.
+type Interface interface {
+	MonitorZookeeperEvents(ctx context.Context, eventsChannel chan spec.ZookeeperClusterWatchEvent)
+	MonitorZookeeperUserEvents(ctx context.Context, eventsChannel chan spec.ZookeeperUserWatchEvent)
+	MonitorZookeeperBackupEvents(ctx context.Context, eventsChannel chan spec.ZookeeperBackupWatchEvent)
+	MonitorOwnedResources(ctx context.Context, eventsChannel chan spec.ZookeeperClusterWatchEvent)
+
+	// HasSynced reports whether all started informers have listed their resources once.
+	HasSynced() bool
+	// InformersRunning returns an error naming the informers that stopped watching.
+	InformersRunning() error
+
+	GetZookeeperCluster(namespace, name string) (*spec.ZookeeperCluster, error)
+	ListZookeeperClusters() ([]spec.ZookeeperCluster, error)
+	UpdateZookeeperCluster(cluster *spec.ZookeeperCluster) (*spec.ZookeeperCluster, error)
+	UpdateZookeeperUser(user *spec.ZookeeperUser) (*spec.ZookeeperUser, error)
+	GetZookeeperBackup(namespace, name string) (*spec.ZookeeperBackup, error)
+	UpdateZookeeperBackup(backup *spec.ZookeeperBackup) (*spec.ZookeeperBackup, error)
+}
+
+var _ Interface = &CustomResourceController{}
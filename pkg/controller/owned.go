@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/metrics"
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+	log "github.com/sirupsen/logrus"
+)
+
+// MonitorOwnedResources watches the StatefulSets, Pods, Services and ConfigMaps of the clusters
+// and sends a RESYNC event of the owning cluster to eventsChannel when one of them changes, until
+// ctx is done. Changes of the same cluster are merged while its event waits to be sent.
+func (c *CustomResourceController) MonitorOwnedResources(ctx context.Context, eventsChannel chan spec.ZookeeperClusterWatchEvent) {
+	methodLogger := logger.WithFields(log.Fields{"method": "MonitorOwnedResources"})
+	methodLogger.Info("Starting Monitoring")
+
+	queue := workqueue.NewNamed("owned-resources")
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueOwner(queue, obj)
+		},
+		UpdateFunc: func(old, new interface{}) {
+			oldObject, err := apimeta.Accessor(old)
+			if err != nil {
+				return
+			}
+			newObject, err := apimeta.Accessor(new)
+			if err != nil || oldObject.GetResourceVersion() == newObject.GetResourceVersion() {
+				return
+			}
+			c.enqueueOwner(queue, new)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			c.enqueueOwner(queue, obj)
+		},
+	}
+
+	owned := map[string]cache.SharedIndexInformer{
+		"statefulsets": c.statefulSetInformer(),
+		"pods":         c.kubeInformerFactory.Core().V1().Pods().Informer(),
+		"services":     c.kubeInformerFactory.Core().V1().Services().Informer(),
+		"configmaps":   c.kubeInformerFactory.Core().V1().ConfigMaps().Informer(),
+	}
+	for name, informer := range owned {
+		informer.AddEventHandler(handler)
+		c.runInformer(name, informer, ctx.Done())
+	}
+
+	go func() {
+		<-ctx.Done()
+		methodLogger.Warn("recieved shutdown signal, stopping informers")
+		queue.ShutDown()
+	}()
+
+	go func() {
+		for {
+			key, shutdown := queue.Get()
+			if shutdown {
+				return
+			}
+			c.resyncCluster(ctx, key.(string), eventsChannel)
+			queue.Done(key)
+		}
+	}()
+}
+
+// statefulSetInformer informs about StatefulSets through the newest StatefulSet API of the API server.
+func (c *CustomResourceController) statefulSetInformer() cache.SharedIndexInformer {
+	version, err := kube.StatefulSetAPI(c.kubeClient.Discovery())
+	if err != nil {
+		logger.WithFields(log.Fields{
+			"method": "statefulSetInformer",
+			"error":  err,
+		}).Error("Cant discover the StatefulSet API, using apps/v1")
+	}
+	switch version {
+	case kube.AppsV1beta2:
+		return c.kubeInformerFactory.Apps().V1beta2().StatefulSets().Informer()
+	case kube.AppsV1beta1:
+		return c.kubeInformerFactory.Apps().V1beta1().StatefulSets().Informer()
+	}
+	return c.kubeInformerFactory.Apps().V1().StatefulSets().Informer()
+}
+
+// enqueueOwner adds the keys of the clusters owning obj to the queue. Pods belong to the cluster
+// of their StatefulSet. Objects created before the operator set owner references belong to all
+// clusters of their namespace.
+func (c *CustomResourceController) enqueueOwner(queue workqueue.Interface, obj interface{}) {
+	object, err := apimeta.Accessor(obj)
+	if err != nil {
+		return
+	}
+	if owner := metav1.GetControllerOf(object); owner != nil {
+		switch owner.Kind {
+		case "ZookeeperCluster", "StatefulSet":
+			queue.Add(object.GetNamespace() + "/" + owner.Name)
+		}
+		return
+	}
+
+	clusters, err := c.informerFactory.Pivotal().V1().ZookeeperClusters().Lister().ZookeeperClusters(object.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		return
+	}
+	for _, cluster := range clusters {
+		queue.Add(cluster.ObjectMeta.Namespace + "/" + cluster.ObjectMeta.Name)
+	}
+}
+
+// resyncCluster sends a RESYNC event of the cluster behind key unless it is gone or being deleted.
+func (c *CustomResourceController) resyncCluster(ctx context.Context, key string, eventsChannel chan spec.ZookeeperClusterWatchEvent) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return
+	}
+	cluster, err := c.GetZookeeperCluster(namespace, name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			logger.WithFields(log.Fields{
+				"method": "resyncCluster",
+				"key":    key,
+				"error":  err,
+			}).Error("Cant get cluster")
+		}
+		return
+	}
+	if cluster.ObjectMeta.DeletionTimestamp != nil {
+		return
+	}
+
+	metrics.ClusterQueue.Add()
+	select {
+	case eventsChannel <- spec.ZookeeperClusterWatchEvent{Type: "RESYNC", Object: *cluster, OldObject: *cluster}:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/client/clientset/versioned/fake"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+func TestMonitorOwnedResources(t *testing.T) {
+	controller := true
+	cluster := &spec.ZookeeperCluster{ObjectMeta: metav1.ObjectMeta{Name: "zk", Namespace: "test", UID: "uid"}}
+	statefulSet := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{
+		Name:      "zk",
+		Namespace: "test",
+		Labels:    map[string]string{"app": "zk"},
+		OwnerReferences: []metav1.OwnerReference{
+			{APIVersion: spec.SchemeGroupVersion.String(), Kind: "ZookeeperCluster", Name: "zk", UID: "uid", Controller: &controller},
+		},
+	}}
+	kubeClient := kubefake.NewSimpleClientset(statefulSet)
+	kubeClient.Resources = []*metav1.APIResourceList{
+		{GroupVersion: kube.AppsV1, APIResources: []metav1.APIResource{{Name: "statefulsets", Namespaced: true, Kind: "StatefulSet"}}},
+	}
+	c := newController(apiextensionsfake.NewSimpleClientset(), kubeClient, fake.NewSimpleClientset(cluster), "test", 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clusterEvents := make(chan spec.ZookeeperClusterWatchEvent, 10)
+	c.MonitorZookeeperEvents(ctx, clusterEvents)
+	events := make(chan spec.ZookeeperClusterWatchEvent, 10)
+	c.MonitorOwnedResources(ctx, events)
+	if !cache.WaitForCacheSync(ctx.Done(), c.HasSynced) {
+		t.Fatal("informers didn't sync")
+	}
+
+	tests := []struct {
+		name   string
+		change func() error
+	}{
+		{
+			name: "delete StatefulSet",
+			change: func() error {
+				return kubeClient.AppsV1().StatefulSets("test").Delete("zk", &metav1.DeleteOptions{})
+			},
+		},
+		{
+			name: "create Pod of the StatefulSet",
+			change: func() error {
+				_, err := kubeClient.CoreV1().Pods("test").Create(&v1.Pod{ObjectMeta: metav1.ObjectMeta{
+					Name:            "zk-0",
+					Namespace:       "test",
+					Labels:          map[string]string{"app": "zk"},
+					OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "zk", Controller: &controller}},
+				}})
+				return err
+			},
+		},
+		{
+			name: "create Service without owner",
+			change: func() error {
+				_, err := kubeClient.CoreV1().Services("test").Create(&v1.Service{ObjectMeta: metav1.ObjectMeta{
+					Name:      "zk-headless",
+					Namespace: "test",
+					Labels:    map[string]string{"app": "zk-headless"},
+				}})
+				return err
+			},
+		},
+	}
+	for _, test := range tests {
+		// the StatefulSet listed at start syncs the cluster once
+		drain(events)
+		if err := test.change(); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		select {
+		case event := <-events:
+			if event.Type != "RESYNC" || event.Object.Name != "zk" {
+				t.Errorf("%s: got %s of %s, want RESYNC of zk", test.name, event.Type, event.Object.Name)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("%s: cluster not synced", test.name)
+		}
+	}
+}
+
+func drain(events chan spec.ZookeeperClusterWatchEvent) {
+	for {
+		select {
+		case <-events:
+		case <-time.After(50 * time.Millisecond):
+			return
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// quantityPattern matches the serialized form of a resource.Quantity.
+const quantityPattern = `^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$`
+
+var (
+	timeType     = reflect.TypeOf(metav1.Time{})
+	quantityType = reflect.TypeOf(resource.Quantity{})
+)
+
+// newValidation builds the OpenAPI v3 schema of a custom resource from the Go type of its spec.
+// Only the spec is validated, the state is written by the operator.
+//
+// Constraints are read from the schema tag of each field, for example
+// `schema:"required,minimum=1,maximum=9"`. Supported are required, minimum, maximum,
+// minLength, pattern, enum (values separated by |) and format=quantity. On slices
+// enum, pattern and format apply to the items.
+func newValidation(spec interface{}) *apiextensionsv1beta1.CustomResourceValidation {
+	return &apiextensionsv1beta1.CustomResourceValidation{
+		OpenAPIV3Schema: &apiextensionsv1beta1.JSONSchemaProps{
+			Type:     "object",
+			Required: []string{"spec"},
+			Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+				"spec": typeSchema(reflect.TypeOf(spec)),
+			},
+		},
+	}
+}
+
+func typeSchema(t reflect.Type) apiextensionsv1beta1.JSONSchemaProps {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return apiextensionsv1beta1.JSONSchemaProps{Type: "string", Format: "date-time"}
+	}
+	if t == quantityType {
+		return apiextensionsv1beta1.JSONSchemaProps{Type: "string", Pattern: quantityPattern}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return apiextensionsv1beta1.JSONSchemaProps{Type: "string"}
+	case reflect.Bool:
+		return apiextensionsv1beta1.JSONSchemaProps{Type: "boolean"}
+	case reflect.Int32, reflect.Int64:
+		return apiextensionsv1beta1.JSONSchemaProps{Type: "integer", Format: t.Kind().String()}
+	case reflect.Int, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return apiextensionsv1beta1.JSONSchemaProps{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return apiextensionsv1beta1.JSONSchemaProps{Type: "number"}
+	case reflect.Slice:
+		items := typeSchema(t.Elem())
+		return apiextensionsv1beta1.JSONSchemaProps{
+			Type:  "array",
+			Items: &apiextensionsv1beta1.JSONSchemaPropsOrArray{Schema: &items},
+		}
+	case reflect.Map:
+		values := typeSchema(t.Elem())
+		return apiextensionsv1beta1.JSONSchemaProps{
+			Type:                 "object",
+			AdditionalProperties: &apiextensionsv1beta1.JSONSchemaPropsOrBool{Allows: true, Schema: &values},
+		}
+	case reflect.Struct:
+		props := apiextensionsv1beta1.JSONSchemaProps{
+			Type:       "object",
+			Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{},
+		}
+		addFields(&props, t)
+		if len(props.Properties) == 0 {
+			props.Properties = nil
+		}
+		return props
+	}
+	panic(fmt.Sprintf("no schema for type %s", t))
+}
+
+func addFields(props *apiextensionsv1beta1.JSONSchemaProps, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || field.PkgPath != "" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			addFields(props, field.Type)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldProps := typeSchema(field.Type)
+		if applyTag(&fieldProps, field.Tag.Get("schema")) {
+			props.Required = append(props.Required, name)
+		}
+		props.Properties[name] = fieldProps
+	}
+}
+
+// applyTag adds the constraints of a schema tag and reports whether the field is required.
+func applyTag(props *apiextensionsv1beta1.JSONSchemaProps, tag string) bool {
+	required := false
+	if tag == "" {
+		return required
+	}
+
+	values := props
+	if props.Type == "array" {
+		values = props.Items.Schema
+	}
+	for _, option := range strings.Split(tag, ",") {
+		key, value := option, ""
+		if i := strings.Index(option, "="); i >= 0 {
+			key, value = option[:i], option[i+1:]
+		}
+
+		switch key {
+		case "required":
+			required = true
+		case "minimum":
+			props.Minimum = parseFloat(value)
+		case "maximum":
+			props.Maximum = parseFloat(value)
+		case "minLength":
+			n := int64(*parseFloat(value))
+			props.MinLength = &n
+		case "pattern":
+			values.Pattern = value
+		case "format":
+			if value != "quantity" {
+				panic(fmt.Sprintf("unknown schema format %q", value))
+			}
+			values.Pattern = quantityPattern
+		case "enum":
+			for _, v := range strings.Split(value, "|") {
+				raw, _ := json.Marshal(v)
+				values.Enum = append(values.Enum, apiextensionsv1beta1.JSON{Raw: raw})
+			}
+		default:
+			panic(fmt.Sprintf("unknown schema option %q", key))
+		}
+	}
+	return required
+}
+
+func parseFloat(value string) *float64 {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		panic(fmt.Sprintf("invalid number %q in schema tag", value))
+	}
+	return &f
+}
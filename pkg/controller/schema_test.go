@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+// patternViolations checks the strings of an object against the patterns of its schema.
+func patternViolations(schema apiextensionsv1beta1.JSONSchemaProps, object interface{}) []string {
+	raw, err := json.Marshal(object)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return []string{err.Error()}
+	}
+	var violations []string
+	for name, value := range fields {
+		props, ok := schema.Properties[name]
+		if !ok {
+			violations = append(violations, fmt.Sprintf("%s is not in the schema", name))
+			continue
+		}
+		s, ok := value.(string)
+		if ok && props.Pattern != "" && !regexp.MustCompile(props.Pattern).MatchString(s) {
+			violations = append(violations, fmt.Sprintf("%s %q doesn't match %s", name, s, props.Pattern))
+		}
+	}
+	return violations
+}
+
+func TestResourcesSchema(t *testing.T) {
+	validation := newValidation(spec.ZookeeperClusterSpec{})
+	schema := validation.OpenAPIV3Schema.Properties["spec"].Properties["resources"]
+
+	// the operator writes back clusters whose resources were never defaulted
+	if violations := patternViolations(schema, spec.ResourceSpec{}); len(violations) > 0 {
+		t.Errorf("zero resources are rejected: %v", violations)
+	}
+	if violations := patternViolations(schema, spec.ResourceSpec{Memory: "1Gi", DiskSpace: "1.5Gi", CPU: "500m", JvmHeap: "512M"}); len(violations) > 0 {
+		t.Errorf("valid resources are rejected: %v", violations)
+	}
+	if violations := patternViolations(schema, spec.ResourceSpec{Memory: "1 GB"}); len(violations) != 1 {
+		t.Errorf("got violations %v for an invalid memory, want one", violations)
+	}
+}
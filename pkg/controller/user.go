@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"context"
+
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/metrics"
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+	log "github.com/sirupsen/logrus"
+)
+
+// UpdateZookeeperUser writes the user, including its state, back to the API server.
+func (c *CustomResourceController) UpdateZookeeperUser(user *spec.ZookeeperUser) (*spec.ZookeeperUser, error) {
+	return c.Client.PivotalV1().ZookeeperUsers(user.ObjectMeta.Namespace).Update(user)
+}
+
+// MonitorZookeeperUserEvents sends the events of the informer to eventsChannel until ctx is done.
+func (c *CustomResourceController) MonitorZookeeperUserEvents(ctx context.Context, eventsChannel chan spec.ZookeeperUserWatchEvent) {
+	methodLogger := logger.WithFields(log.Fields{"method": "MonitorZookeeperUserEvents"})
+	methodLogger.Info("Starting Monitoring")
+
+	send := func(event spec.ZookeeperUserWatchEvent) {
+		metrics.UserQueue.Add()
+		select {
+		case eventsChannel <- event:
+		case <-ctx.Done():
+			// nobody receives anymore, dropping the event lets the informer stop
+		}
+	}
+	informer := c.informerFactory.Pivotal().V1().ZookeeperUsers().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			user := obj.(*spec.ZookeeperUser)
+			methodLogger.WithFields(log.Fields{"watchFunction": "ADDED"}).Info(spec.PrintUser(user))
+			var event spec.ZookeeperUserWatchEvent
+			event.Type = "ADDED"
+			event.Object = *user
+			send(event)
+		},
+
+		UpdateFunc: func(old, new interface{}) {
+			oldUser := old.(*spec.ZookeeperUser)
+			newUser := new.(*spec.ZookeeperUser)
+			methodLogger.WithFields(log.Fields{
+				"eventType": "UPDATED",
+				"old":       spec.PrintUser(oldUser),
+				"new":       spec.PrintUser(newUser),
+			}).Info("Recieved Update Event")
+			var event spec.ZookeeperUserWatchEvent
+			event.Type = "UPDATED"
+			event.Object = *newUser
+			event.OldObject = *oldUser
+			send(event)
+		},
+
+		DeleteFunc: func(obj interface{}) {
+			user, ok := obj.(*spec.ZookeeperUser)
+			if !ok {
+				tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					return
+				}
+				if user, ok = tombstone.Obj.(*spec.ZookeeperUser); !ok {
+					return
+				}
+			}
+			methodLogger.WithFields(log.Fields{"watchFunction": "DELETED"}).Info(spec.PrintUser(user))
+			var event spec.ZookeeperUserWatchEvent
+			event.Type = "DELETED"
+			event.Object = *user
+			send(event)
+		},
+	})
+
+	c.runInformer(spec.CRDUserRessourcePlural, informer, ctx.Done())
+
+	go func() {
+		<-ctx.Done()
+		methodLogger.Warn("recieved shutdown signal, stopping informer")
+	}()
+}
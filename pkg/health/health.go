@@ -0,0 +1,98 @@
+// Package health serves the health, readiness and debug endpoints of the operator.
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/leader"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/processor"
+)
+
+type status struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Identity string `json:"identity,omitempty"`
+	Leader   string `json:"leader,omitempty"`
+	Leading  bool   `json:"leading"`
+}
+
+// Handler serves /healthz, /readyz, /clusters and, if enabled, /debug/pprof.
+type Handler struct {
+	*http.ServeMux
+	processor *processor.Processor
+	// elector is nil without leader election, the replica then always leads
+	elector *leader.Elector
+}
+
+func New(processor *processor.Processor, elector *leader.Elector, enablePprof bool) *Handler {
+	h := &Handler{
+		ServeMux:  http.NewServeMux(),
+		processor: processor,
+		elector:   elector,
+	}
+	h.HandleFunc("/healthz", h.healthz)
+	h.HandleFunc("/readyz", h.readyz)
+	h.HandleFunc("/clusters", h.clusters)
+	if enablePprof {
+		h.HandleFunc("/debug/pprof/", pprof.Index)
+		h.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		h.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		h.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		h.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+	return h
+}
+
+func (h *Handler) status() status {
+	if h.elector == nil {
+		return status{Status: "ok", Leading: true}
+	}
+	return status{
+		Status:   "ok",
+		Identity: h.elector.Identity(),
+		Leader:   h.elector.Leader(),
+		Leading:  h.elector.IsLeader(),
+	}
+}
+
+// healthz fails if the informers or the event loop of the leader stopped. Standby replicas
+// run neither and are healthy as long as they answer.
+func (h *Handler) healthz(w http.ResponseWriter, r *http.Request) {
+	current := h.status()
+	code := http.StatusOK
+	if current.Leading {
+		if err := h.processor.Healthy(); err != nil {
+			current.Status = "error"
+			current.Error = err.Error()
+			code = http.StatusInternalServerError
+		}
+	}
+	writeJSON(w, code, current)
+}
+
+// readyz succeeds once the replica leads and its informers have synced.
+func (h *Handler) readyz(w http.ResponseWriter, r *http.Request) {
+	current := h.status()
+	code := http.StatusOK
+	switch {
+	case !current.Leading:
+		current.Status = "not leading"
+		code = http.StatusServiceUnavailable
+	case !h.processor.Synced():
+		current.Status = "caches not synced"
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, current)
+}
+
+func (h *Handler) clusters(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.processor.Clusters())
+}
+
+func writeJSON(w http.ResponseWriter, code int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,98 @@
+package kube
+
+import (
+	"strings"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientscheme "k8s.io/client-go/kubernetes/scheme"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+// FieldManager is the manager the operator applies objects as in the server-side apply mode.
+const FieldManager = "zookeeper-operator"
+
+// createOrApply writes object with server-side apply if the mode is enabled, otherwise with
+// createOrUpdate.
+func (k *Kubernetes) createOrApply(cluster spec.ZookeeperCluster, object runtime.Object, createOrUpdate func() error) error {
+	if !k.ServerSideApply {
+		return createOrUpdate()
+	}
+	return k.apply(cluster, object)
+}
+
+// apply sends object as the fields it owns to the API server. If other managers own some of them,
+// the conflict is recorded as an event on the cluster and the fields are taken over.
+func (k *Kubernetes) apply(cluster spec.ZookeeperCluster, object runtime.Object) error {
+	kinds, _, err := clientscheme.Scheme.ObjectKinds(object)
+	if err != nil {
+		return err
+	}
+	kind := kinds[0]
+	resource, _ := apimeta.UnsafeGuessKindToResource(kind)
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return err
+	}
+	applied := &unstructured.Unstructured{Object: content}
+	applied.SetGroupVersionKind(kind)
+	// fields the operator doesn't set would otherwise be owned with their zero values
+	unstructured.RemoveNestedField(applied.Object, "status")
+	unstructured.RemoveNestedField(applied.Object, "metadata", "creationTimestamp")
+	data, err := applied.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	methodLogger := logger.WithFields(log.Fields{
+		"method":    "apply",
+		"kind":      kind.Kind,
+		"name":      applied.GetName(),
+		"namespace": applied.GetNamespace(),
+	})
+	resources := k.Dynamic.Resource(resource).Namespace(applied.GetNamespace())
+	_, err = resources.Patch(applied.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{FieldManager: FieldManager})
+	if !errors.IsConflict(err) {
+		if err != nil {
+			methodLogger.WithField("error", err).Error("Cant apply object")
+		}
+		return err
+	}
+
+	fields := conflictingFields(err)
+	methodLogger.WithField("conflicts", fields).Warn("Taking over fields owned by other managers")
+	k.Recorder.Eventf(&cluster, v1.EventTypeWarning, EventReasonApplyConflict, "Took over fields of %s %s from other managers: %s", kind.Kind, applied.GetName(), fields)
+	force := true
+	_, err = resources.Patch(applied.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{FieldManager: FieldManager, Force: &force})
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant apply object")
+	}
+	return err
+}
+
+// conflictingFields describes the fields and managers of a conflict returned by an apply.
+func conflictingFields(err error) string {
+	status, ok := err.(errors.APIStatus)
+	if !ok || status.Status().Details == nil {
+		return err.Error()
+	}
+	var fields []string
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type == metav1.CauseTypeFieldManagerConflict {
+			fields = append(fields, cause.Message)
+		}
+	}
+	if len(fields) == 0 {
+		return err.Error()
+	}
+	return strings.Join(fields, ", ")
+}
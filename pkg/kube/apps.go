@@ -0,0 +1,172 @@
+package kube
+
+import (
+	"fmt"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+)
+
+// StatefulSet APIs the operator can use, newest first. apps/v1 is served since Kubernetes 1.9.
+const (
+	AppsV1      = "apps/v1"
+	AppsV1beta2 = "apps/v1beta2"
+	AppsV1beta1 = "apps/v1beta1"
+)
+
+var statefulSetAPIs = []string{AppsV1, AppsV1beta2, AppsV1beta1}
+
+// StatefulSetAPI returns the newest StatefulSet API served by the API server.
+func StatefulSetAPI(client discovery.DiscoveryInterface) (string, error) {
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return "", err
+	}
+	served := make(map[string]bool)
+	for _, group := range groups.Groups {
+		for _, version := range group.Versions {
+			served[version.GroupVersion] = true
+		}
+	}
+	for _, groupVersion := range statefulSetAPIs {
+		if served[groupVersion] {
+			return groupVersion, nil
+		}
+	}
+	return "", fmt.Errorf("API server serves none of the StatefulSet APIs %v", statefulSetAPIs)
+}
+
+// discoveredAPI caches the StatefulSet API for all copies of a Kubernetes, failed discoveries are retried.
+type discoveredAPI struct {
+	mutex   sync.Mutex
+	version string
+}
+
+func (k *Kubernetes) statefulSetAPI() (string, error) {
+	if k.discovered == nil {
+		return StatefulSetAPI(k.Client.Discovery())
+	}
+	k.discovered.mutex.Lock()
+	defer k.discovered.mutex.Unlock()
+	if k.discovered.version == "" {
+		version, err := StatefulSetAPI(k.Client.Discovery())
+		if err != nil {
+			return "", err
+		}
+		k.discovered.version = version
+	}
+	return k.discovered.version, nil
+}
+
+// statefulSetClient reads and writes apps/v1 StatefulSets through the newest StatefulSet API of
+// the API server. StatefulSets created through the beta APIs are the same objects and are read
+// and updated in place.
+type statefulSetClient struct {
+	k         *Kubernetes
+	namespace string
+}
+
+func (k *Kubernetes) statefulSets(namespace string) statefulSetClient {
+	return statefulSetClient{k: k, namespace: namespace}
+}
+
+func (c statefulSetClient) Get(name string, options metav1.GetOptions) (*appsv1.StatefulSet, error) {
+	version, err := c.k.statefulSetAPI()
+	if err != nil {
+		return nil, err
+	}
+	var result runtime.Object
+	switch version {
+	case AppsV1beta2:
+		result, err = c.k.Client.AppsV1beta2().StatefulSets(c.namespace).Get(name, options)
+	case AppsV1beta1:
+		result, err = c.k.Client.AppsV1beta1().StatefulSets(c.namespace).Get(name, options)
+	default:
+		return c.k.Client.AppsV1().StatefulSets(c.namespace).Get(name, options)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toAppsV1(result)
+}
+
+func (c statefulSetClient) Create(statefulSet *appsv1.StatefulSet) (*appsv1.StatefulSet, error) {
+	return c.write(statefulSet, false)
+}
+
+func (c statefulSetClient) Update(statefulSet *appsv1.StatefulSet) (*appsv1.StatefulSet, error) {
+	return c.write(statefulSet, true)
+}
+
+func (c statefulSetClient) write(statefulSet *appsv1.StatefulSet, update bool) (*appsv1.StatefulSet, error) {
+	version, err := c.k.statefulSetAPI()
+	if err != nil {
+		return nil, err
+	}
+	var result runtime.Object
+	switch version {
+	case AppsV1beta2:
+		beta := &appsv1beta2.StatefulSet{}
+		if err := convertStatefulSet(statefulSet, beta); err != nil {
+			return nil, err
+		}
+		if update {
+			result, err = c.k.Client.AppsV1beta2().StatefulSets(c.namespace).Update(beta)
+		} else {
+			result, err = c.k.Client.AppsV1beta2().StatefulSets(c.namespace).Create(beta)
+		}
+	case AppsV1beta1:
+		beta := &appsv1beta1.StatefulSet{}
+		if err := convertStatefulSet(statefulSet, beta); err != nil {
+			return nil, err
+		}
+		if update {
+			result, err = c.k.Client.AppsV1beta1().StatefulSets(c.namespace).Update(beta)
+		} else {
+			result, err = c.k.Client.AppsV1beta1().StatefulSets(c.namespace).Create(beta)
+		}
+	default:
+		if update {
+			return c.k.Client.AppsV1().StatefulSets(c.namespace).Update(statefulSet)
+		}
+		return c.k.Client.AppsV1().StatefulSets(c.namespace).Create(statefulSet)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toAppsV1(result)
+}
+
+func (c statefulSetClient) Delete(name string, options *metav1.DeleteOptions) error {
+	version, err := c.k.statefulSetAPI()
+	if err != nil {
+		return err
+	}
+	switch version {
+	case AppsV1beta2:
+		return c.k.Client.AppsV1beta2().StatefulSets(c.namespace).Delete(name, options)
+	case AppsV1beta1:
+		return c.k.Client.AppsV1beta1().StatefulSets(c.namespace).Delete(name, options)
+	}
+	return c.k.Client.AppsV1().StatefulSets(c.namespace).Delete(name, options)
+}
+
+func toAppsV1(statefulSet runtime.Object) (*appsv1.StatefulSet, error) {
+	result := &appsv1.StatefulSet{}
+	return result, convertStatefulSet(statefulSet, result)
+}
+
+// convertStatefulSet copies a StatefulSet between the versions of the apps API. They share the
+// fields the operator uses.
+func convertStatefulSet(in, out interface{}) error {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(in)
+	if err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(content, out)
+}
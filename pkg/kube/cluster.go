@@ -4,26 +4,62 @@ import (
 	"github.com/liwang-pivotal/zookeeper-operator/spec"
 )
 
+// CreateCluster creates or updates the objects of the cluster and records what changed as events
+// on the ZookeeperCluster.
 func CreateCluster(cluster spec.ZookeeperCluster, client Kubernetes) error {
+	return StartMembers(cluster, cluster.Spec.BrokerCount, client)
+}
+
+// StartMembers creates or updates the objects of the cluster like CreateCluster, but only runs
+// the first replicas members. The configuration of the members still lists all of them.
+func StartMembers(cluster spec.ZookeeperCluster, replicas int32, client Kubernetes) error {
 
 	headlessSVC := generateHeadlessService(cluster)
-	err := client.CreateOrUpdateService(headlessSVC)
+	err := client.createOrApply(cluster, headlessSVC, func() error { return client.CreateOrUpdateService(headlessSVC) })
 	if err != nil {
+		client.recordSyncFailure(cluster, "create or update", "Service", headlessSVC.Name, err)
 		return err
 	}
 
 	configMap := generateConfigMap(cluster)
-	err = client.CreateOrUpdateConfigMap(configMap)
+	previousConfigMap, err := client.getConfigMap(configMap)
+	if err != nil {
+		client.recordSyncFailure(cluster, "get", "ConfigMap", configMap.Name, err)
+		return err
+	}
+	err = client.createOrApply(cluster, configMap, func() error { return client.CreateOrUpdateConfigMap(configMap) })
 	if err != nil {
+		client.recordSyncFailure(cluster, "create or update", "ConfigMap", configMap.Name, err)
 		return err
 	}
 
 	sts := generateZookeeperStatefulset(cluster)
-	err = client.CreateOrUpdateStatefulSet(sts)
+	sts.Spec.Replicas = &replicas
+	previousSts, err := client.getStatefulSet(sts)
+	if err != nil {
+		client.recordSyncFailure(cluster, "get", "StatefulSet", sts.Name, err)
+		return err
+	}
+	if previousSts != nil {
+		err = client.replaceStatefulSet(previousSts, sts)
+		if err != nil {
+			client.recordSyncFailure(cluster, "replace", "StatefulSet", sts.Name, err)
+			return err
+		}
+	}
+	err = client.createOrApply(cluster, sts, func() error { return client.CreateOrUpdateStatefulSet(sts) })
 	if err != nil {
+		client.recordSyncFailure(cluster, "create or update", "StatefulSet", sts.Name, err)
 		return err
 	}
 
+	err = syncServiceMonitor(cluster, client)
+	if err != nil {
+		client.recordSyncFailure(cluster, "create or update", "ServiceMonitor", cluster.ObjectMeta.Name, err)
+		return err
+	}
+
+	client.recordClusterChanges(cluster, previousSts, sts, previousConfigMap, configMap)
 	return nil
 }
 
@@ -46,5 +82,12 @@ func DeleteCluster(cluster spec.ZookeeperCluster, client Kubernetes) error {
 		return err
 	}
 
+	available, err := client.serviceMonitorsAvailable()
+	if err != nil {
+		return err
+	}
+	if available {
+		return client.deleteServiceMonitor(cluster.ObjectMeta.Namespace, cluster.ObjectMeta.Name)
+	}
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,618 @@
+package kube
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+const testNamespace = "test"
+
+// newTestClient returns a client backed by the fake clientset of client-go on which the
+// ServiceMonitor API of the Prometheus Operator is installed.
+func newTestClient(objects ...runtime.Object) (Kubernetes, *fake.Clientset, *record.FakeRecorder) {
+	clientset := fake.NewSimpleClientset(objects...)
+	clientset.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: AppsV1,
+			APIResources: []metav1.APIResource{{Name: "statefulsets", Namespaced: true, Kind: "StatefulSet"}},
+		},
+		{
+			GroupVersion: serviceMonitorResource.GroupVersion().String(),
+			APIResources: []metav1.APIResource{{Name: serviceMonitorResource.Resource, Namespaced: true, Kind: "ServiceMonitor"}},
+		},
+	}
+	recorder := record.NewFakeRecorder(100)
+	client := Kubernetes{
+		Client:   clientset,
+		Recorder: recorder,
+		Dynamic:  dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()),
+	}
+	return client, clientset, recorder
+}
+
+func testCluster(brokers int32) spec.ZookeeperCluster {
+	return spec.ZookeeperCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "zk", Namespace: testNamespace},
+		Spec:       spec.ZookeeperClusterSpec{BrokerCount: brokers},
+	}
+}
+
+// recordedReasons drains the recorder and returns the reasons of the recorded events.
+func recordedReasons(recorder *record.FakeRecorder) []string {
+	var reasons []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			// events are formatted as "<type> <reason> <message>"
+			reasons = append(reasons, strings.Fields(event)[1])
+		default:
+			return reasons
+		}
+	}
+}
+
+// mutatingActions returns the verb and resource of every create, update and delete call.
+func mutatingActions(clientset *fake.Clientset) []string {
+	var actions []string
+	for _, action := range clientset.Actions() {
+		switch action.GetVerb() {
+		case "create", "update", "delete":
+			actions = append(actions, action.GetVerb()+" "+action.GetResource().Resource)
+		}
+	}
+	return actions
+}
+
+func TestCreateCluster(t *testing.T) {
+	client, clientset, recorder := newTestClient()
+	cluster := testCluster(3)
+
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("CreateCluster: %v", err)
+	}
+
+	sts, err := clientset.AppsV1().StatefulSets(testNamespace).Get("zk", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("StatefulSet not created: %v", err)
+	}
+	if *sts.Spec.Replicas != 3 || sts.Spec.ServiceName != "zk-headless" {
+		t.Errorf("StatefulSet has %d replicas behind %q, want 3 behind zk-headless", *sts.Spec.Replicas, sts.Spec.ServiceName)
+	}
+
+	svc, err := clientset.CoreV1().Services(testNamespace).Get("zk-headless", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Service not created: %v", err)
+	}
+	if svc.Spec.ClusterIP != "None" || len(svc.Spec.Ports) != 3 {
+		t.Errorf("Service is not headless with 3 ports: %v", svc.Spec)
+	}
+	if svc.Spec.PublishNotReadyAddresses {
+		t.Error("Service publishes members before they are ready")
+	}
+
+	configMap, err := clientset.CoreV1().ConfigMaps(testNamespace).Get("zk-config", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("ConfigMap not created: %v", err)
+	}
+	if got, want := configMap.Data["ensemble"], "zk-0;zk-1;zk-2"; got != want {
+		t.Errorf("ensemble is %q, want %q", got, want)
+	}
+	if got, want := configMap.Data["jvm.heap"], "100M"; got != want {
+		t.Errorf("jvm.heap is %q, want %q within the default memory", got, want)
+	}
+
+	if reasons := recordedReasons(recorder); len(reasons) != 1 || reasons[0] != EventReasonCreated {
+		t.Errorf("recorded %v, want [%s]", reasons, EventReasonCreated)
+	}
+}
+
+func TestCreateClusterIsIdempotent(t *testing.T) {
+	client, clientset, recorder := newTestClient()
+	cluster := testCluster(3)
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("CreateCluster: %v", err)
+	}
+	recordedReasons(recorder)
+	clientset.ClearActions()
+
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("second CreateCluster: %v", err)
+	}
+	for _, action := range mutatingActions(clientset) {
+		if strings.HasPrefix(action, "create") || strings.HasPrefix(action, "delete") {
+			t.Errorf("second CreateCluster called %s", action)
+		}
+	}
+	if reasons := recordedReasons(recorder); len(reasons) != 0 {
+		t.Errorf("second CreateCluster recorded %v, want no events", reasons)
+	}
+
+	services, _ := clientset.CoreV1().Services(testNamespace).List(metav1.ListOptions{})
+	configMaps, _ := clientset.CoreV1().ConfigMaps(testNamespace).List(metav1.ListOptions{})
+	statefulSets, _ := clientset.AppsV1().StatefulSets(testNamespace).List(metav1.ListOptions{})
+	if len(services.Items) != 1 || len(configMaps.Items) != 1 || len(statefulSets.Items) != 1 {
+		t.Errorf("got %d Services, %d ConfigMaps and %d StatefulSets, want one each",
+			len(services.Items), len(configMaps.Items), len(statefulSets.Items))
+	}
+}
+
+func TestCreateClusterUpdates(t *testing.T) {
+	tests := []struct {
+		name    string
+		update  func(*spec.ZookeeperCluster)
+		reasons []string
+	}{
+		{
+			name:    "scale",
+			update:  func(cluster *spec.ZookeeperCluster) { cluster.Spec.BrokerCount = 5 },
+			reasons: []string{EventReasonScaled, EventReasonUpdated},
+		},
+		{
+			name:    "image",
+			update:  func(cluster *spec.ZookeeperCluster) { cluster.Spec.Image = "zookeeper:3.5" },
+			reasons: []string{EventReasonRollingRestart},
+		},
+		{
+			name:    "heap",
+			update:  func(cluster *spec.ZookeeperCluster) { cluster.Spec.Resources.JvmHeap = "1Gi" },
+			reasons: []string{EventReasonUpdated},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, clientset, recorder := newTestClient()
+			cluster := testCluster(3)
+			if err := CreateCluster(cluster, client); err != nil {
+				t.Fatalf("CreateCluster: %v", err)
+			}
+			recordedReasons(recorder)
+
+			test.update(&cluster)
+			if err := CreateCluster(cluster, client); err != nil {
+				t.Fatalf("CreateCluster after update: %v", err)
+			}
+
+			sts, _ := clientset.AppsV1().StatefulSets(testNamespace).Get("zk", metav1.GetOptions{})
+			if *sts.Spec.Replicas != cluster.Spec.BrokerCount {
+				t.Errorf("StatefulSet has %d replicas, want %d", *sts.Spec.Replicas, cluster.Spec.BrokerCount)
+			}
+			configMap, _ := clientset.CoreV1().ConfigMaps(testNamespace).Get("zk-config", metav1.GetOptions{})
+			if got, want := configMap.Data["ensemble"], ensemble(cluster); got != want {
+				t.Errorf("ensemble is %q, want %q", got, want)
+			}
+			if got := recordedReasons(recorder); strings.Join(got, ",") != strings.Join(test.reasons, ",") {
+				t.Errorf("recorded %v, want %v", got, test.reasons)
+			}
+		})
+	}
+}
+
+func TestCreateClusterRecordsSyncFailure(t *testing.T) {
+	client, clientset, recorder := newTestClient()
+	clientset.PrependReactor("create", "statefulsets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("quota exceeded")
+	})
+
+	err := CreateCluster(testCluster(3), client)
+	if err == nil {
+		t.Fatal("CreateCluster succeeded although the StatefulSet couldn't be created")
+	}
+	if reasons := recordedReasons(recorder); len(reasons) != 1 || reasons[0] != EventReasonSyncFailed {
+		t.Errorf("recorded %v, want [%s]", reasons, EventReasonSyncFailed)
+	}
+}
+
+func TestDeleteCluster(t *testing.T) {
+	client, clientset, _ := newTestClient()
+	cluster := testCluster(3)
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("CreateCluster: %v", err)
+	}
+
+	if err := DeleteCluster(cluster, client); err != nil {
+		t.Fatalf("DeleteCluster: %v", err)
+	}
+	if _, err := clientset.AppsV1().StatefulSets(testNamespace).Get("zk", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("StatefulSet still exists: %v", err)
+	}
+	if _, err := clientset.CoreV1().Services(testNamespace).Get("zk-headless", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Service still exists: %v", err)
+	}
+	if _, err := clientset.CoreV1().ConfigMaps(testNamespace).Get("zk-config", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("ConfigMap still exists: %v", err)
+	}
+
+	clientset.ClearActions()
+	if err := DeleteCluster(cluster, client); err != nil {
+		t.Fatalf("DeleteCluster of a deleted cluster: %v", err)
+	}
+	if actions := mutatingActions(clientset); len(actions) != 0 {
+		t.Errorf("DeleteCluster of a deleted cluster called %v", actions)
+	}
+}
+
+func TestServiceMonitor(t *testing.T) {
+	client, _, _ := newTestClient()
+	cluster := testCluster(3)
+	cluster.Spec.Monitoring = &spec.MonitoringSpec{Exporter: spec.MonitoringExporterNative}
+	serviceMonitors := client.Dynamic.Resource(serviceMonitorResource).Namespace(testNamespace)
+
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("CreateCluster: %v", err)
+	}
+	if _, err := serviceMonitors.Get("zk", metav1.GetOptions{}); err != nil {
+		t.Fatalf("ServiceMonitor not created: %v", err)
+	}
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("second CreateCluster: %v", err)
+	}
+
+	cluster.Spec.Monitoring = nil
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("CreateCluster without monitoring: %v", err)
+	}
+	if _, err := serviceMonitors.Get("zk", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("ServiceMonitor of an unmonitored cluster still exists: %v", err)
+	}
+}
+
+func TestCreateClusterSkipsUnchangedObjects(t *testing.T) {
+	client, clientset, _ := newTestClient()
+	cluster := testCluster(3)
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("CreateCluster: %v", err)
+	}
+	clientset.ClearActions()
+
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("second CreateCluster: %v", err)
+	}
+	if actions := mutatingActions(clientset); len(actions) != 0 {
+		t.Errorf("CreateCluster of an unchanged cluster called %v", actions)
+	}
+}
+
+func TestCreateClusterKeepsForeignFields(t *testing.T) {
+	client, clientset, _ := newTestClient()
+	cluster := testCluster(3)
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("CreateCluster: %v", err)
+	}
+
+	statefulSets := clientset.AppsV1().StatefulSets(testNamespace)
+	sts, _ := statefulSets.Get("zk", metav1.GetOptions{})
+	sts.Labels["team"] = "storage"
+	sts.Spec.Template.Annotations = map[string]string{"kubectl.kubernetes.io/restartedAt": "now"}
+	sts.Spec.Template.Spec.Containers[0].TerminationMessagePath = "/dev/termination-log"
+	sts.Spec.UpdateStrategy.Type = appsv1.OnDeleteStatefulSetStrategyType
+	statefulSets.Update(sts)
+	services := clientset.CoreV1().Services(testNamespace)
+	svc, _ := services.Get("zk-headless", metav1.GetOptions{})
+	svc.Spec.Ports[0].TargetPort = intstr.FromInt(2888)
+	services.Update(svc)
+	clientset.ClearActions()
+
+	// fields the API server defaults or others set don't cause updates
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("second CreateCluster: %v", err)
+	}
+	if actions := mutatingActions(clientset); len(actions) != 0 {
+		t.Errorf("CreateCluster with foreign fields called %v", actions)
+	}
+
+	cluster.Spec.BrokerCount = 5
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("CreateCluster after scaling: %v", err)
+	}
+	sts, _ = statefulSets.Get("zk", metav1.GetOptions{})
+	if *sts.Spec.Replicas != 5 {
+		t.Errorf("StatefulSet has %d replicas, want 5", *sts.Spec.Replicas)
+	}
+	if sts.Labels["team"] != "storage" || sts.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"] != "now" ||
+		sts.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType {
+		t.Errorf("update dropped foreign fields: labels %v, template annotations %v, update strategy %q",
+			sts.Labels, sts.Spec.Template.Annotations, sts.Spec.UpdateStrategy.Type)
+	}
+}
+
+func TestCreateClusterRepairsDrift(t *testing.T) {
+	client, clientset, _ := newTestClient()
+	cluster := testCluster(3)
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("CreateCluster: %v", err)
+	}
+
+	statefulSets := clientset.AppsV1().StatefulSets(testNamespace)
+	sts, _ := statefulSets.Get("zk", metav1.GetOptions{})
+	sts.Spec.Template.Spec.Containers[0].Image = "zookeeper:edited"
+	statefulSets.Update(sts)
+	configMaps := clientset.CoreV1().ConfigMaps(testNamespace)
+	configMap, _ := configMaps.Get("zk-config", metav1.GetOptions{})
+	configMap.Data["ensemble"] = "zk-0"
+	configMap.Data["unknown"] = "value"
+	configMaps.Update(configMap)
+	clientset.CoreV1().Services(testNamespace).Delete("zk-headless", &metav1.DeleteOptions{})
+
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("CreateCluster: %v", err)
+	}
+	sts, _ = statefulSets.Get("zk", metav1.GetOptions{})
+	if image := sts.Spec.Template.Spec.Containers[0].Image; image != zookeeperImage(cluster) {
+		t.Errorf("image is %q, want %q", image, zookeeperImage(cluster))
+	}
+	configMap, _ = configMaps.Get("zk-config", metav1.GetOptions{})
+	if _, ok := configMap.Data["unknown"]; ok || configMap.Data["ensemble"] != ensemble(cluster) {
+		t.Errorf("ConfigMap data not restored: %v", configMap.Data)
+	}
+	if _, err := clientset.CoreV1().Services(testNamespace).Get("zk-headless", metav1.GetOptions{}); err != nil {
+		t.Errorf("Service not recreated: %v", err)
+	}
+}
+
+func TestCreateClusterServerSideApply(t *testing.T) {
+	client, clientset, recorder := newTestClient()
+	client.ServerSideApply = true
+	var applied []string
+	conflict := true
+	client.Dynamic.(*dynamicfake.FakeDynamicClient).PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			t.Errorf("%s patched with %s", patch.GetResource().Resource, patch.GetPatchType())
+		}
+		if !strings.Contains(string(patch.GetPatch()), `"apiVersion":`) {
+			t.Errorf("applied %s without apiVersion: %s", patch.GetResource().Resource, patch.GetPatch())
+		}
+		applied = append(applied, patch.GetResource().Resource)
+		if patch.GetResource().Resource == "statefulsets" && conflict {
+			conflict = false
+			err := apierrors.NewConflict(appsv1.Resource("statefulsets"), patch.GetName(), errors.New("conflict"))
+			err.ErrStatus.Details.Causes = []metav1.StatusCause{{
+				Type:    metav1.CauseTypeFieldManagerConflict,
+				Message: `conflict with "kubectl" using apps/v1: .spec.replicas`,
+			}}
+			return true, nil, err
+		}
+		return true, nil, nil
+	})
+
+	if err := CreateCluster(testCluster(3), client); err != nil {
+		t.Fatalf("CreateCluster: %v", err)
+	}
+	if got, want := strings.Join(applied, ","), "services,configmaps,statefulsets,statefulsets"; got != want {
+		t.Errorf("applied %s, want %s", got, want)
+	}
+	if actions := mutatingActions(clientset); len(actions) != 0 {
+		t.Errorf("server-side apply mode called %v", actions)
+	}
+	reasons := recordedReasons(recorder)
+	if len(reasons) == 0 || reasons[0] != EventReasonApplyConflict {
+		t.Errorf("recorded %v, want %s first", reasons, EventReasonApplyConflict)
+	}
+}
+
+func TestCreateClusterAdoptsBetaStatefulSet(t *testing.T) {
+	cluster := testCluster(3)
+	// a StatefulSet created through apps/v1beta1 by earlier versions, as apps/v1 returns it
+	existing := generateZookeeperStatefulset(cluster)
+	existing.Spec.Template.Annotations = map[string]string{"pod.alpha.kubernetes.io/initialized": "true"}
+	existing.Spec.UpdateStrategy.Type = appsv1.OnDeleteStatefulSetStrategyType
+	claim := &existing.Spec.VolumeClaimTemplates[0]
+	claim.Annotations = map[string]string{"volume.beta.kubernetes.io/storage-class": "standard"}
+	claim.Spec.StorageClassName = nil
+	client, clientset, _ := newTestClient(existing)
+
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("CreateCluster: %v", err)
+	}
+	for _, action := range mutatingActions(clientset) {
+		if strings.HasSuffix(action, "statefulsets") {
+			t.Errorf("adopting the StatefulSet called %s", action)
+		}
+	}
+}
+
+func TestStatefulSetAPIFallback(t *testing.T) {
+	client, clientset, _ := newTestClient()
+	clientset.Resources[0].GroupVersion = AppsV1beta2
+	cluster := testCluster(3)
+
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("CreateCluster: %v", err)
+	}
+	sts, err := clientset.AppsV1beta2().StatefulSets(testNamespace).Get("zk", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("StatefulSet not created through apps/v1beta2: %v", err)
+	}
+	if *sts.Spec.Replicas != 3 {
+		t.Errorf("StatefulSet has %d replicas, want 3", *sts.Spec.Replicas)
+	}
+
+	cluster.Spec.BrokerCount = 5
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("CreateCluster after scaling: %v", err)
+	}
+	sts, _ = clientset.AppsV1beta2().StatefulSets(testNamespace).Get("zk", metav1.GetOptions{})
+	if *sts.Spec.Replicas != 5 {
+		t.Errorf("StatefulSet has %d replicas, want 5", *sts.Spec.Replicas)
+	}
+	if err := DeleteCluster(cluster, client); err != nil {
+		t.Fatalf("DeleteCluster: %v", err)
+	}
+	if _, err := clientset.AppsV1beta2().StatefulSets(testNamespace).Get("zk", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("StatefulSet still exists: %v", err)
+	}
+}
+
+func TestCreateClusterDropsPrivileges(t *testing.T) {
+	cluster := testCluster(3)
+	// a StatefulSet of an earlier version, which ran ZooKeeper privileged as root
+	existing := generateZookeeperStatefulset(cluster)
+	privileged := true
+	existing.Spec.Template.Spec.SecurityContext = nil
+	existing.Spec.Template.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{Privileged: &privileged}
+	client, clientset, _ := newTestClient(existing)
+
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("CreateCluster: %v", err)
+	}
+	sts, _ := clientset.AppsV1().StatefulSets(testNamespace).Get("zk", metav1.GetOptions{})
+	pod := sts.Spec.Template.Spec
+	container := pod.Containers[0].SecurityContext
+	if container == nil || container.Privileged == nil || *container.Privileged {
+		t.Errorf("ZooKeeper container still runs privileged: %v", container)
+	}
+	if pod.SecurityContext == nil || pod.SecurityContext.FSGroup == nil || *pod.SecurityContext.FSGroup != zookeeperUser {
+		t.Errorf("pod security context %v has no fsGroup %d", pod.SecurityContext, zookeeperUser)
+	}
+}
+
+func TestSecurityContextOverrides(t *testing.T) {
+	cluster := testCluster(3)
+	user, readOnly := int64(2000), false
+	cluster.Spec.SecurityContext = &spec.SecurityContextSpec{
+		Pod:       &corev1.PodSecurityContext{RunAsUser: &user},
+		Container: &corev1.SecurityContext{ReadOnlyRootFilesystem: &readOnly},
+	}
+
+	pod := generateZookeeperStatefulset(cluster).Spec.Template.Spec
+	if *pod.SecurityContext.RunAsUser != 2000 || *pod.SecurityContext.FSGroup != zookeeperUser || !*pod.SecurityContext.RunAsNonRoot {
+		t.Errorf("pod security context %+v doesn't combine the override with the defaults", pod.SecurityContext)
+	}
+	container := pod.Containers[0].SecurityContext
+	if *container.ReadOnlyRootFilesystem || *container.Privileged || len(container.Capabilities.Drop) != 1 {
+		t.Errorf("container security context %+v doesn't combine the override with the defaults", container)
+	}
+}
+
+func TestProbes(t *testing.T) {
+	cluster := testCluster(3)
+	pod := generateZookeeperStatefulset(cluster).Spec.Template.Spec
+	readiness := pod.Containers[0].ReadinessProbe
+	if readiness.Exec == nil || readiness.Exec.Command[0] != "zkOk.sh" || readiness.InitialDelaySeconds != 10 || readiness.TimeoutSeconds != 5 {
+		t.Errorf("default readiness probe is %+v, want zkOk.sh after 10s with a timeout of 5s", readiness)
+	}
+
+	cluster.Spec.Probes = &spec.ProbesSpec{
+		Mode:      spec.ProbeModeZkutil,
+		Image:     "zkutil:test",
+		Readiness: &spec.ProbeTimings{PeriodSeconds: 2, FailureThreshold: 5},
+	}
+	pod = generateZookeeperStatefulset(cluster).Spec.Template.Spec
+	readiness, liveness := pod.Containers[0].ReadinessProbe, pod.Containers[0].LivenessProbe
+	if got := strings.Join(readiness.Exec.Command, " "); got != "/opt/zkutil/zkutil probe -check ready" {
+		t.Errorf("readiness probe runs %q", got)
+	}
+	if readiness.PeriodSeconds != 2 || readiness.FailureThreshold != 5 || readiness.InitialDelaySeconds != 10 {
+		t.Errorf("readiness probe %+v doesn't combine the timings with the defaults", readiness)
+	}
+	if got := strings.Join(liveness.Exec.Command, " "); got != "/opt/zkutil/zkutil probe -check live" || liveness.PeriodSeconds != 0 {
+		t.Errorf("liveness probe runs %q every %ds", got, liveness.PeriodSeconds)
+	}
+	if len(pod.InitContainers) != 1 || pod.InitContainers[0].Image != "zkutil:test" || pod.InitContainers[0].SecurityContext == nil {
+		t.Errorf("init containers %+v don't install zkutil", pod.InitContainers)
+	}
+
+	cluster.Spec.Probes = &spec.ProbesSpec{Mode: spec.ProbeModeTCP}
+	pod = generateZookeeperStatefulset(cluster).Spec.Template.Spec
+	if socket := pod.Containers[0].LivenessProbe.TCPSocket; socket == nil || socket.Port.StrVal != "client" {
+		t.Errorf("liveness probe %+v doesn't connect to the client port", pod.Containers[0].LivenessProbe)
+	}
+	if len(pod.InitContainers) != 0 {
+		t.Errorf("tcp probes added init containers %+v", pod.InitContainers)
+	}
+}
+
+func TestCreateClusterWithStartup(t *testing.T) {
+	client, clientset, _ := newTestClient()
+	cluster := testCluster(3)
+	cluster.Spec.Startup = &spec.StartupSpec{Image: "zkutil:test", DynamicConfig: true}
+	cluster.Spec.Probes = &spec.ProbesSpec{Mode: spec.ProbeModeZkutil, Image: "zkutil:test"}
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("CreateCluster: %v", err)
+	}
+
+	sts, _ := clientset.AppsV1().StatefulSets(testNamespace).Get("zk", metav1.GetOptions{})
+	pod := sts.Spec.Template.Spec
+	if len(pod.InitContainers) != 2 || pod.InitContainers[0].Name != "init" {
+		t.Fatalf("init containers %+v don't start with zkutil init", pod.InitContainers)
+	}
+	if got := strings.Join(pod.InitContainers[0].Command, " "); got != "zkutil init -config-dir /etc/zookeeper-operator -timeout 5m -dynamic" {
+		t.Errorf("init container runs %q", got)
+	}
+	if command := pod.Containers[0].Command[2]; command != "zkServer.sh start-foreground" {
+		t.Errorf("ZooKeeper starts with %q", command)
+	}
+
+	configMap, _ := clientset.CoreV1().ConfigMaps(testNamespace).Get("zk-config", metav1.GetOptions{})
+	if config := configMap.Data["zoo.cfg"]; !strings.Contains(config, "tickTime=2000\n") || !strings.Contains(config, "dataDir=/var/lib/zookeeper/data\n") {
+		t.Errorf("zoo.cfg is missing settings:\n%s", config)
+	}
+	if _, ok := configMap.Data["java.env"]; !ok {
+		t.Error("java.env not rendered")
+	}
+	svc, _ := clientset.CoreV1().Services(testNamespace).Get("zk-headless", metav1.GetOptions{})
+	if !svc.Spec.PublishNotReadyAddresses {
+		t.Error("Service doesn't publish members before they are ready")
+	}
+}
+
+func TestCreateClusterReplacesStatefulSetForZkutilProbes(t *testing.T) {
+	cluster := testCluster(3)
+	existing := generateZookeeperStatefulset(cluster)
+	existing.Spec.PodManagementPolicy = ""
+	client, clientset, _ := newTestClient(existing)
+
+	cluster.Spec.Probes = &spec.ProbesSpec{Mode: spec.ProbeModeZkutil, Image: "zkutil:test"}
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("CreateCluster: %v", err)
+	}
+	sts, err := clientset.AppsV1().StatefulSets(testNamespace).Get("zk", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("StatefulSet not recreated: %v", err)
+	}
+	if sts.Spec.PodManagementPolicy != appsv1.ParallelPodManagement {
+		t.Errorf("pod management policy is %q, want %q", sts.Spec.PodManagementPolicy, appsv1.ParallelPodManagement)
+	}
+	actions := len(clientset.Actions())
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("CreateCluster: %v", err)
+	}
+	for _, action := range clientset.Actions()[actions:] {
+		if action.GetVerb() == "delete" {
+			t.Errorf("StatefulSet with the right policy replaced again: %v", action)
+		}
+	}
+}
+
+func TestCreateClusterStopsPublishingUnreadyMembers(t *testing.T) {
+	cluster := testCluster(3)
+	cluster.Spec.Startup = &spec.StartupSpec{Image: "zkutil:test"}
+	client, clientset, _ := newTestClient()
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("CreateCluster: %v", err)
+	}
+
+	cluster.Spec.Startup = nil
+	if err := CreateCluster(cluster, client); err != nil {
+		t.Fatalf("CreateCluster: %v", err)
+	}
+	svc, _ := clientset.CoreV1().Services(testNamespace).Get("zk-headless", metav1.GetOptions{})
+	if svc.Spec.PublishNotReadyAddresses {
+		t.Error("Service still publishes members before they are ready")
+	}
+}
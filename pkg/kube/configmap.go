@@ -1,23 +1,32 @@
 package kube
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/liwang-pivotal/zookeeper-operator/spec"
 
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/util/retry"
 )
 
 func generateConfigMap(cluster spec.ZookeeperCluster) *v1.ConfigMap {
+	// the webhook defaults the resources, but it may not be deployed
+	spec.SetDefaults(&cluster.Spec)
 	configMap := &v1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "zk-config",
+			Labels: createLabels(cluster),
 			Namespace: cluster.ObjectMeta.Namespace,
+			OwnerReferences: clusterOwnerReferences(cluster),
 		},
 		Data: map[string]string{
-			"ensemble": "zk-0;zk-1;zk-2",
-			"jvm.heap": "512M",
+			"ensemble": ensemble(cluster),
+			"jvm.heap": jvmHeap(cluster),
 			"tick": "2000",
 			"init": "10",
 			"sync": "5",
@@ -26,10 +35,36 @@ func generateConfigMap(cluster spec.ZookeeperCluster) *v1.ConfigMap {
 			"purge.interval": "1",
 		},
 	}
+	if cluster.Spec.Monitoring != nil && cluster.Spec.Monitoring.Exporter == spec.MonitoringExporterJMX {
+		configMap.Data[jmxExporterConfigKey] = jmxExporterConfig
+	}
+	if cluster.Spec.Startup != nil {
+		for name, content := range startupConfig(cluster, configMap.Data) {
+			configMap.Data[name] = content
+		}
+	}
 
 	return configMap
 }
 
+// ensemble lists the host names of all members, the pods of the StatefulSet.
+func ensemble(cluster spec.ZookeeperCluster) string {
+	members := make([]string, 0, cluster.Spec.BrokerCount)
+	for i := int32(0); i < cluster.Spec.BrokerCount; i++ {
+		members = append(members, fmt.Sprintf("%s-%d", cluster.ObjectMeta.Name, i))
+	}
+	return strings.Join(members, ";")
+}
+
+// jvmHeap converts the heap of the cluster into the -Xmx notation, in megabytes.
+func jvmHeap(cluster spec.ZookeeperCluster) string {
+	heap, err := resource.ParseQuantity(cluster.Spec.Resources.JvmHeap)
+	if err != nil {
+		return "512M"
+	}
+	return fmt.Sprintf("%dM", heap.Value()/(1024*1024))
+}
+
 func (k *Kubernetes) CreateOrUpdateConfigMap(configMap *v1.ConfigMap) error {
 	methodLogger := logger.WithFields(log.Fields{
 		"method":    "CreateOrUpdateConfigMap",
@@ -81,14 +116,47 @@ func (k *Kubernetes) IfConfigMapExists(configMap *v1.ConfigMap) (bool, error) {
 	return true, nil
 }
 
+// getConfigMap returns the current version of configMap, or nil if it doesn't exist.
+func (k *Kubernetes) getConfigMap(configMap *v1.ConfigMap) (*v1.ConfigMap, error) {
+	current, err := k.Client.CoreV1().ConfigMaps(configMap.ObjectMeta.Namespace).Get(configMap.ObjectMeta.Name, k.DefaultOption)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	return current, err
+}
+
 func (k *Kubernetes) createConfigMap(configMap *v1.ConfigMap) error {
 	_, err := k.Client.CoreV1().ConfigMaps(configMap.ObjectMeta.Namespace).Create(configMap)
 	return err
 }
 
+// updateConfigMap writes the metadata and data of configMap to the live ConfigMap if they differ.
+// The data is owned as a whole, keys the operator doesn't generate anymore are removed.
 func (k *Kubernetes) updateConfigMap(configMap *v1.ConfigMap) error {
-	_, err := k.Client.CoreV1().ConfigMaps(configMap.ObjectMeta.Namespace).Update(configMap)
-	return err
+	configMaps := k.Client.CoreV1().ConfigMaps(configMap.ObjectMeta.Namespace)
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		live, err := configMaps.Get(configMap.ObjectMeta.Name, k.DefaultOption)
+		if err != nil {
+			return err
+		}
+		diffs := metaDiff(live.ObjectMeta, configMap.ObjectMeta)
+		diffs = append(diffs, ownedDiff("data", live.Data, configMap.Data)...)
+		for key := range live.Data {
+			if _, ok := configMap.Data[key]; !ok {
+				diffs = append(diffs, fmt.Sprintf("data[%s]: removed", key))
+			}
+		}
+		if len(diffs) == 0 {
+			return nil
+		}
+		logUpdate("ConfigMap", live.ObjectMeta, diffs)
+
+		updated := live.DeepCopy()
+		mergeMeta(&updated.ObjectMeta, configMap.ObjectMeta)
+		updated.Data = configMap.Data
+		_, err = configMaps.Update(updated)
+		return err
+	})
 }
 
 func (k *Kubernetes) deleteConfigMap(configMap *v1.ConfigMap) error {
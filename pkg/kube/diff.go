@@ -0,0 +1,189 @@
+package kube
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var quantityType = reflect.TypeOf(resource.Quantity{})
+
+// ownedDiff lists the fields set in desired which differ in live, one "path: live -> desired" entry
+// each. Fields left empty in desired are not owned by the operator, the API server defaults them or
+// other controllers set them, and are ignored. Pointers set in desired are owned even if they point
+// to a zero value. Lists are owned as a whole, maps only by the keys set in desired.
+func ownedDiff(path string, live, desired interface{}) []string {
+	var diffs []string
+	collectDiff(path, reflect.ValueOf(live), reflect.ValueOf(desired), &diffs)
+	return diffs
+}
+
+func collectDiff(path string, live, desired reflect.Value, diffs *[]string) {
+	if isEmpty(desired) {
+		return
+	}
+	if desired.Type() == quantityType {
+		liveQuantity, desiredQuantity := live.Interface().(resource.Quantity), desired.Interface().(resource.Quantity)
+		if liveQuantity.Cmp(desiredQuantity) != 0 {
+			*diffs = append(*diffs, fmt.Sprintf("%s: %s -> %s", path, liveQuantity.String(), desiredQuantity.String()))
+		}
+		return
+	}
+
+	switch desired.Kind() {
+	case reflect.Ptr:
+		if live.IsNil() {
+			*diffs = append(*diffs, fmt.Sprintf("%s: <nil> -> %s", path, format(desired)))
+			return
+		}
+		if desired.Elem().Kind() != reflect.Struct {
+			if !reflect.DeepEqual(live.Elem().Interface(), desired.Elem().Interface()) {
+				*diffs = append(*diffs, fmt.Sprintf("%s: %s -> %s", path, format(live), format(desired)))
+			}
+			return
+		}
+		collectDiff(path, live.Elem(), desired.Elem(), diffs)
+
+	case reflect.Struct:
+		for i := 0; i < desired.NumField(); i++ {
+			field := desired.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			collectDiff(fieldPath(path, field), live.Field(i), desired.Field(i), diffs)
+		}
+
+	case reflect.Slice:
+		if live.Len() != desired.Len() {
+			*diffs = append(*diffs, fmt.Sprintf("%s: %d items -> %d items", path, live.Len(), desired.Len()))
+			return
+		}
+		for i := 0; i < desired.Len(); i++ {
+			collectDiff(fmt.Sprintf("%s[%d]", path, i), live.Index(i), desired.Index(i), diffs)
+		}
+
+	case reflect.Map:
+		keys := desired.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, key := range keys {
+			keyPath := fmt.Sprintf("%s[%v]", path, key)
+			liveValue := live.MapIndex(key)
+			if !liveValue.IsValid() {
+				*diffs = append(*diffs, fmt.Sprintf("%s: <none> -> %s", keyPath, format(desired.MapIndex(key))))
+				continue
+			}
+			collectDiff(keyPath, liveValue, desired.MapIndex(key), diffs)
+		}
+
+	default:
+		if !reflect.DeepEqual(live.Interface(), desired.Interface()) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: %s -> %s", path, format(live), format(desired)))
+		}
+	}
+}
+
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	case reflect.Map, reflect.Slice:
+		return value.Len() == 0
+	}
+	return reflect.DeepEqual(value.Interface(), reflect.Zero(value.Type()).Interface())
+}
+
+// fieldPath appends the JSON name of field to path, inlined fields keep the path.
+func fieldPath(path string, field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" {
+		if field.Anonymous {
+			return path
+		}
+		name = field.Name
+	}
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func format(value reflect.Value) string {
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.String {
+		return fmt.Sprintf("%q", value.String())
+	}
+	if value.Type() == quantityType {
+		quantity := value.Interface().(resource.Quantity)
+		return quantity.String()
+	}
+	return fmt.Sprintf("%v", value.Interface())
+}
+
+// metaDiff lists the labels, annotations and owner references of desired missing or differing in live.
+func metaDiff(live, desired metav1.ObjectMeta) []string {
+	diffs := ownedDiff("metadata.labels", live.Labels, desired.Labels)
+	diffs = append(diffs, ownedDiff("metadata.annotations", live.Annotations, desired.Annotations)...)
+	for _, owner := range desired.OwnerReferences {
+		if !hasOwnerReference(live, owner) {
+			diffs = append(diffs, fmt.Sprintf("metadata.ownerReferences: missing %s %s", owner.Kind, owner.Name))
+		}
+	}
+	return diffs
+}
+
+// mergeMeta sets the labels, annotations and owner references of desired on live and keeps the others.
+func mergeMeta(live *metav1.ObjectMeta, desired metav1.ObjectMeta) {
+	live.Labels = mergeStrings(live.Labels, desired.Labels)
+	live.Annotations = mergeStrings(live.Annotations, desired.Annotations)
+	for _, owner := range desired.OwnerReferences {
+		if !hasOwnerReference(*live, owner) {
+			live.OwnerReferences = append(live.OwnerReferences, owner)
+		}
+	}
+}
+
+func mergeStrings(live, desired map[string]string) map[string]string {
+	if len(desired) == 0 {
+		return live
+	}
+	merged := make(map[string]string, len(live)+len(desired))
+	for key, value := range live {
+		merged[key] = value
+	}
+	for key, value := range desired {
+		merged[key] = value
+	}
+	return merged
+}
+
+// hasOwnerReference reports whether object references owner. An object can only have one
+// controller, objects shared by clusters keep the reference to the first one.
+func hasOwnerReference(object metav1.ObjectMeta, owner metav1.OwnerReference) bool {
+	for _, reference := range object.OwnerReferences {
+		if reference.UID == owner.UID || (isController(reference) && isController(owner)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isController(reference metav1.OwnerReference) bool {
+	return reference.Controller != nil && *reference.Controller
+}
+
+// logUpdate logs the differences an update of an object applies.
+func logUpdate(kind string, object metav1.ObjectMeta, diffs []string) {
+	logger.WithFields(log.Fields{
+		"kind":      kind,
+		"name":      object.Name,
+		"namespace": object.Namespace,
+		"diff":      strings.Join(diffs, "; "),
+	}).Info("Updating changed fields")
+}
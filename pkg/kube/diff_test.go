@@ -0,0 +1,70 @@
+package kube
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestOwnedDiff(t *testing.T) {
+	privileged, unprivileged := true, false
+	tests := []struct {
+		name    string
+		live    v1.Container
+		desired v1.Container
+		diffs   []string
+	}{
+		{
+			name:    "defaulted fields",
+			live:    v1.Container{Name: "zk", Image: "zk:1", ImagePullPolicy: v1.PullAlways, TerminationMessagePath: "/dev/termination-log"},
+			desired: v1.Container{Name: "zk", Image: "zk:1"},
+		},
+		{
+			name:    "changed field",
+			live:    v1.Container{Name: "zk", Image: "zk:1"},
+			desired: v1.Container{Name: "zk", Image: "zk:2"},
+			diffs:   []string{`image: "zk:1" -> "zk:2"`},
+		},
+		{
+			name: "equal quantities",
+			live: v1.Container{Resources: v1.ResourceRequirements{
+				Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+			}},
+			desired: v1.Container{Resources: v1.ResourceRequirements{
+				Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1000m")},
+			}},
+		},
+		{
+			name: "missing key",
+			live: v1.Container{Resources: v1.ResourceRequirements{
+				Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+			}},
+			desired: v1.Container{Resources: v1.ResourceRequirements{
+				Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")},
+			}},
+			diffs: []string{"resources.limits[memory]: <none> -> 1Gi"},
+		},
+		{
+			name:    "pointer to zero value",
+			live:    v1.Container{SecurityContext: &v1.SecurityContext{Privileged: &privileged}},
+			desired: v1.Container{SecurityContext: &v1.SecurityContext{Privileged: &unprivileged}},
+			diffs:   []string{"securityContext.privileged: true -> false"},
+		},
+		{
+			name:    "list length",
+			live:    v1.Container{Args: []string{"a"}},
+			desired: v1.Container{Args: []string{"a", "b"}},
+			diffs:   []string{"args: 1 items -> 2 items"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diffs := ownedDiff("", test.live, test.desired)
+			if strings.Join(diffs, "\n") != strings.Join(test.diffs, "\n") {
+				t.Errorf("got %q, want %q", diffs, test.diffs)
+			}
+		})
+	}
+}
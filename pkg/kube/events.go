@@ -0,0 +1,83 @@
+package kube
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientscheme "k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+// Reasons of the events recorded on ZookeeperClusters, shown by kubectl describe.
+const (
+	EventReasonCreated         = "Created"
+	EventReasonUpdated         = "Updated"
+	EventReasonScaled          = "Scaled"
+	EventReasonRollingRestart  = "RollingRestart"
+	EventReasonSyncFailed      = "SyncFailed"
+	EventReasonApplyConflict   = "ApplyConflict"
+	EventReasonBackupSucceeded = "BackupSucceeded"
+	EventReasonBackupFailed    = "BackupFailed"
+	EventReasonQuorumLost      = "QuorumLost"
+	EventReasonQuorumRestored  = "QuorumRestored"
+)
+
+// NewEventRecorder returns a recorder that publishes Kubernetes events on the
+// operator's custom resources and on built-in objects.
+func NewEventRecorder(client Kubernetes) record.EventRecorder {
+	scheme := runtime.NewScheme()
+	clientscheme.AddToScheme(scheme)
+	spec.AddToScheme(scheme)
+
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartLogging(logger.Debugf)
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{
+		Interface: client.Client.CoreV1().Events(""),
+	})
+	return broadcaster.NewRecorder(scheme, v1.EventSource{Component: "zookeeper-operator"})
+}
+
+// recordSyncFailure records a failed API call made on behalf of the cluster.
+func (k *Kubernetes) recordSyncFailure(cluster spec.ZookeeperCluster, action, kind, name string, err error) {
+	k.Recorder.Eventf(&cluster, v1.EventTypeWarning, EventReasonSyncFailed, "Cant %s %s %s: %v", action, kind, name, err)
+}
+
+// recordClusterChanges records what applying the generated StatefulSet and ConfigMap changed on
+// the cluster. The previous objects are nil if they didn't exist.
+func (k *Kubernetes) recordClusterChanges(cluster spec.ZookeeperCluster, previous, current *appsv1.StatefulSet, previousConfig, currentConfig *v1.ConfigMap) {
+	if previous == nil {
+		k.Recorder.Eventf(&cluster, v1.EventTypeNormal, EventReasonCreated, "Created ensemble of %d members", *current.Spec.Replicas)
+		return
+	}
+	if previous.Spec.Replicas != nil && *previous.Spec.Replicas != *current.Spec.Replicas {
+		k.Recorder.Eventf(&cluster, v1.EventTypeNormal, EventReasonScaled, "Scaled ensemble from %d to %d members", *previous.Spec.Replicas, *current.Spec.Replicas)
+	}
+	if podTemplateChanged(previous.Spec.Template, current.Spec.Template) {
+		k.Recorder.Eventf(&cluster, v1.EventTypeNormal, EventReasonRollingRestart, "Restarting %d members one at a time to apply the changed pod template", *current.Spec.Replicas)
+	}
+	if previousConfig != nil && !apiequality.Semantic.DeepEqual(previousConfig.Data, currentConfig.Data) {
+		k.Recorder.Eventf(&cluster, v1.EventTypeNormal, EventReasonUpdated, "Updated ensemble configuration in ConfigMap %s", currentConfig.ObjectMeta.Name)
+	}
+}
+
+// podTemplateChanged reports whether updating a StatefulSet from previous to current replaces its pods.
+// Only the fields set by the operator are compared, the API server defaults the others.
+func podTemplateChanged(previous, current v1.PodTemplateSpec) bool {
+	if len(previous.Spec.Containers) != len(current.Spec.Containers) {
+		return true
+	}
+	for i, container := range current.Spec.Containers {
+		old := previous.Spec.Containers[i]
+		if old.Image != container.Image ||
+			!apiequality.Semantic.DeepEqual(old.Command, container.Command) ||
+			!apiequality.Semantic.DeepEqual(old.Env, container.Env) ||
+			!apiequality.Semantic.DeepEqual(old.Resources, container.Resources) {
+			return true
+		}
+	}
+	return false
+}
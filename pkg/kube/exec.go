@@ -0,0 +1,57 @@
+package kube
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// Exec runs command in a container of a running pod. stdin may be nil, stdout
+// receives the output of the command. The error includes the command's stderr.
+func (k *Kubernetes) Exec(namespace, pod, container string, command []string, stdin io.Reader, stdout io.Writer) error {
+	methodLogger := logger.WithFields(log.Fields{
+		"method":    "Exec",
+		"pod":       pod,
+		"namespace": namespace,
+		"command":   command,
+	})
+
+	req := k.Client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod).
+		Namespace(namespace).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     stdin != nil,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(k.Config, "POST", req.URL())
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant create executor")
+		return err
+	}
+
+	var stderr bytes.Buffer
+	err = executor.Stream(remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		methodLogger.WithFields(log.Fields{
+			"error":  err,
+			"stderr": stderr.String(),
+		}).Error("Command failed")
+		return fmt.Errorf("exec in %s/%s failed: %v: %s", namespace, pod, err, stderr.String())
+	}
+	return nil
+}
@@ -2,11 +2,15 @@ package kube
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	k8sclient "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
 
 	log "github.com/sirupsen/logrus"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/metrics"
 )
 
 var (
@@ -16,17 +20,38 @@ var (
 )
 
 type Kubernetes struct {
-	Client        *k8sclient.Clientset
+	// Client is a kubernetes.Interface, so tests can pass the fake clientset of client-go.
+	Client        k8sclient.Interface
+	Config        *rest.Config
 	MasterHost    string
 	DefaultOption metav1.GetOptions
 	DeleteOption  metav1.DeleteOptions
+	// Recorder publishes events on the objects managed by the operator.
+	Recorder record.EventRecorder
+	// Dynamic manages objects of optional APIs, like the ServiceMonitors of the Prometheus Operator.
+	Dynamic dynamic.Interface
+	// ServerSideApply writes the Service, ConfigMap and StatefulSet of clusters with server-side
+	// apply as FieldManager instead of comparing and updating them.
+	ServerSideApply bool
+
+	discovered *discoveredAPI
 }
 
 func New(kubeConfigFile, masterHost string) (*Kubernetes, error) {
 	methodLogger := logger.WithFields(log.Fields{"method": "New"})
 
 	// Create the client config. Use kubeconfig if given, otherwise assume in-cluster.
-	client, err := NewKubeClient(kubeConfigFile)
+	config, err := BuildConfig(kubeConfigFile)
+	if err != nil {
+		methodLogger.WithFields(log.Fields{
+			"error":  err,
+			"config": kubeConfigFile,
+		}).Error("could not build Kubernetes client config")
+		return nil, err
+	}
+	config.WrapTransport = metrics.InstrumentTransport
+
+	client, err := k8sclient.NewForConfig(config)
 	if err != nil {
 		methodLogger.WithFields(log.Fields{
 			"error":  err,
@@ -36,10 +61,23 @@ func New(kubeConfigFile, masterHost string) (*Kubernetes, error) {
 		return nil, err
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		methodLogger.WithFields(log.Fields{
+			"error":  err,
+			"config": kubeConfigFile,
+		}).Error("could not init dynamic Kubernetes client")
+		return nil, err
+	}
+
 	k := &Kubernetes{
 		Client:     client,
+		Config:     config,
 		MasterHost: masterHost,
+		Dynamic:    dynamicClient,
+		discovered: &discoveredAPI{},
 	}
+	k.Recorder = NewEventRecorder(*k)
 	methodLogger.WithFields(log.Fields{
 		"config": kubeConfigFile,
 		"client": client,
@@ -0,0 +1,240 @@
+package kube
+
+import (
+	"fmt"
+	"strconv"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+const (
+	metricsPortName = "metrics"
+
+	// jmxPort is the port ZooKeeper serves JMX on for the exporter sidecar, inside the pod only
+	jmxPort              = 9999
+	jmxExporterConfigKey = "jmx-exporter.yml"
+	jmxExporterConfigDir = "/etc/jmx-exporter"
+
+	prometheusMetricsProvider = "org.apache.zookeeper.metrics.prometheus.PrometheusMetricsProvider"
+)
+
+// jmxExporterConfig translates the ZooKeeper MBeans of an ensemble member, following the
+// ZooKeeper example of the JMX exporter.
+var jmxExporterConfig = `hostPort: localhost:` + strconv.Itoa(jmxPort) + `
+lowercaseOutputName: true
+rules:
+- pattern: "org.apache.ZooKeeperService<name0=ReplicatedServer_id(\\d+)><>(\\w+)"
+  name: "zookeeper_$2"
+  type: GAUGE
+- pattern: "org.apache.ZooKeeperService<name0=ReplicatedServer_id(\\d+), name1=replica.(\\d+)><>(\\w+)"
+  name: "zookeeper_$3"
+  type: GAUGE
+  labels:
+    replicaId: "$2"
+- pattern: "org.apache.ZooKeeperService<name0=ReplicatedServer_id(\\d+), name1=replica.(\\d+), name2=(\\w+)><>(Packets\\w+)"
+  name: "zookeeper_$4"
+  type: COUNTER
+  labels:
+    replicaId: "$2"
+    memberType: "$3"
+- pattern: "org.apache.ZooKeeperService<name0=ReplicatedServer_id(\\d+), name1=replica.(\\d+), name2=(\\w+)><>(\\w+)"
+  name: "zookeeper_$4"
+  type: GAUGE
+  labels:
+    replicaId: "$2"
+    memberType: "$3"
+- pattern: "org.apache.ZooKeeperService<name0=ReplicatedServer_id(\\d+), name1=replica.(\\d+), name2=(\\w+), name3=(\\w+)><>(\\w+)"
+  name: "zookeeper_$4_$5"
+  type: GAUGE
+  labels:
+    replicaId: "$2"
+    memberType: "$3"
+- pattern: "org.apache.ZooKeeperService<name0=StandaloneServer_port(\\d+)><>(\\w+)"
+  name: "zookeeper_$2"
+  type: GAUGE
+- pattern: "org.apache.ZooKeeperService<name0=StandaloneServer_port(\\d+), name1=InMemoryDataTree><>(\\w+)"
+  name: "zookeeper_$2"
+  type: GAUGE
+`
+
+var serviceMonitorResource = schema.GroupVersionResource{
+	Group:    "monitoring.coreos.com",
+	Version:  "v1",
+	Resource: "servicemonitors",
+}
+
+// metricsPort returns the port the members serve metrics on, false if monitoring is disabled.
+func metricsPort(cluster spec.ZookeeperCluster) (int32, bool) {
+	monitoring := cluster.Spec.Monitoring
+	if monitoring == nil {
+		return 0, false
+	}
+	if monitoring.Port == 0 {
+		return spec.DefaultMetricsPort, true
+	}
+	return monitoring.Port, true
+}
+
+// metricsConfig returns the zoo.cfg settings that enable the native metrics provider.
+func metricsConfig(cluster spec.ZookeeperCluster) []string {
+	port, enabled := metricsPort(cluster)
+	if !enabled || cluster.Spec.Monitoring.Exporter != spec.MonitoringExporterNative {
+		return nil
+	}
+	return []string{
+		"metricsProvider.className=" + prometheusMetricsProvider,
+		fmt.Sprintf("metricsProvider.httpPort=%d", port),
+	}
+}
+
+// addMonitoring exposes the metrics port on the pod of every member, running the JMX exporter
+// as a sidecar if requested. The ZooKeeper container is the first one of the pod.
+func addMonitoring(cluster spec.ZookeeperCluster, pod *v1.PodSpec) {
+	port, enabled := metricsPort(cluster)
+	if !enabled {
+		return
+	}
+	containerPort := v1.ContainerPort{
+		Name:          metricsPortName,
+		ContainerPort: port,
+		Protocol:      v1.ProtocolTCP,
+	}
+
+	zookeeper := &pod.Containers[0]
+	if cluster.Spec.Monitoring.Exporter != spec.MonitoringExporterJMX {
+		zookeeper.Ports = append(zookeeper.Ports, containerPort)
+		return
+	}
+	// zkServer.sh enables remote JMX without authentication on JMXPORT
+	zookeeper.Env = append(zookeeper.Env, v1.EnvVar{Name: "JMXPORT", Value: strconv.Itoa(jmxPort)})
+
+	image := cluster.Spec.Monitoring.Image
+	if image == "" {
+		image = spec.DefaultJmxExporterImage
+	}
+	pod.Containers = append(pod.Containers, v1.Container{
+		Name:  "jmx-exporter",
+		Image: image,
+		Args:  []string{strconv.Itoa(int(port)), jmxExporterConfigDir + "/" + jmxExporterConfigKey},
+		Ports: []v1.ContainerPort{containerPort},
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("10m"),
+				v1.ResourceMemory: resource.MustParse("64Mi"),
+			},
+		},
+		VolumeMounts: []v1.VolumeMount{{
+			Name:      "jmx-exporter-config",
+			MountPath: jmxExporterConfigDir,
+		}},
+	})
+	pod.Volumes = append(pod.Volumes, v1.Volume{
+		Name: "jmx-exporter-config",
+		VolumeSource: v1.VolumeSource{
+			ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{Name: "zk-config"},
+				Items: []v1.KeyToPath{{
+					Key:  jmxExporterConfigKey,
+					Path: jmxExporterConfigKey,
+				}},
+			},
+		},
+	})
+}
+
+func generateServiceMonitor(cluster spec.ZookeeperCluster) *unstructured.Unstructured {
+	monitoring := cluster.Spec.Monitoring
+	endpoint := map[string]interface{}{
+		"port": metricsPortName,
+	}
+	labels := map[string]interface{}{}
+	if monitoring.ServiceMonitor != nil {
+		if monitoring.ServiceMonitor.Interval != "" {
+			endpoint["interval"] = monitoring.ServiceMonitor.Interval
+		}
+		for key, value := range monitoring.ServiceMonitor.Labels {
+			labels[key] = value
+		}
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": serviceMonitorResource.GroupVersion().String(),
+		"kind":       "ServiceMonitor",
+		"metadata": map[string]interface{}{
+			"name":      cluster.ObjectMeta.Name,
+			"namespace": cluster.ObjectMeta.Namespace,
+			"labels":    labels,
+		},
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{
+					"app": "zk-headless",
+				},
+			},
+			"namespaceSelector": map[string]interface{}{
+				"matchNames": []interface{}{cluster.ObjectMeta.Namespace},
+			},
+			"endpoints": []interface{}{endpoint},
+		},
+	}}
+}
+
+// serviceMonitorsAvailable reports whether the CRDs of the Prometheus Operator are installed.
+func (k *Kubernetes) serviceMonitorsAvailable() (bool, error) {
+	resources, err := k.Client.Discovery().ServerResourcesForGroupVersion(serviceMonitorResource.GroupVersion().String())
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	for _, apiResource := range resources.APIResources {
+		if apiResource.Name == serviceMonitorResource.Resource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// syncServiceMonitor creates or updates the ServiceMonitor of a monitored cluster and deletes
+// the one of an unmonitored cluster, if the Prometheus Operator is installed.
+func syncServiceMonitor(cluster spec.ZookeeperCluster, client Kubernetes) error {
+	available, err := client.serviceMonitorsAvailable()
+	if err != nil || !available {
+		return err
+	}
+	if _, enabled := metricsPort(cluster); !enabled {
+		return client.deleteServiceMonitor(cluster.ObjectMeta.Namespace, cluster.ObjectMeta.Name)
+	}
+	return client.CreateOrUpdateServiceMonitor(generateServiceMonitor(cluster))
+}
+
+func (k *Kubernetes) CreateOrUpdateServiceMonitor(serviceMonitor *unstructured.Unstructured) error {
+	serviceMonitors := k.Dynamic.Resource(serviceMonitorResource).Namespace(serviceMonitor.GetNamespace())
+	current, err := serviceMonitors.Get(serviceMonitor.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = serviceMonitors.Create(serviceMonitor, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	serviceMonitor.SetResourceVersion(current.GetResourceVersion())
+	_, err = serviceMonitors.Update(serviceMonitor, metav1.UpdateOptions{})
+	return err
+}
+
+func (k *Kubernetes) deleteServiceMonitor(namespace, name string) error {
+	err := k.Dynamic.Resource(serviceMonitorResource).Namespace(namespace).Delete(name, &metav1.DeleteOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,30 @@
+package kube
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+// ManagedLabelSelector selects the StatefulSets, Pods, Services and ConfigMaps of the clusters,
+// the operator watches them to repair changes made behind its back.
+const ManagedLabelSelector = "app in (zk, zk-headless)"
+
+// clusterOwnerReferences marks an object as controlled by the cluster, which lets the operator
+// map changes of the object back to the cluster and the garbage collector remove it with the cluster.
+func clusterOwnerReferences(cluster spec.ZookeeperCluster) []metav1.OwnerReference {
+	if cluster.ObjectMeta.UID == "" {
+		// the API server rejects references without UID
+		return nil
+	}
+	controller := true
+	return []metav1.OwnerReference{
+		{
+			APIVersion: spec.SchemeGroupVersion.String(),
+			Kind:       "ZookeeperCluster",
+			Name:       cluster.ObjectMeta.Name,
+			UID:        cluster.ObjectMeta.UID,
+			Controller: &controller,
+		},
+	}
+}
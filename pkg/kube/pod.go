@@ -0,0 +1,82 @@
+package kube
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	podPollInterval = 2 * time.Second
+	podPollTimeout  = 5 * time.Minute
+)
+
+func (k *Kubernetes) createPod(pod *v1.Pod) error {
+	_, err := k.Client.CoreV1().Pods(pod.ObjectMeta.Namespace).Create(pod)
+	return err
+}
+
+func (k *Kubernetes) deletePod(namespace, name string) error {
+	err := k.Client.CoreV1().Pods(namespace).Delete(name, &metav1.DeleteOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// Poll checks condition every interval until it is done or fails. It gives up with
+// wait.ErrWaitTimeout after timeout and with the error of ctx once ctx is done.
+func Poll(ctx context.Context, interval, timeout time.Duration, condition wait.ConditionFunc) error {
+	pollCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := wait.PollUntil(interval, condition, pollCtx.Done())
+	if err == wait.ErrWaitTimeout && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// WaitForPodRunning blocks until all containers of the pod are running.
+func (k *Kubernetes) WaitForPodRunning(ctx context.Context, namespace, name string) error {
+	methodLogger := logger.WithFields(log.Fields{
+		"method":    "WaitForPodRunning",
+		"name":      name,
+		"namespace": namespace,
+	})
+	methodLogger.Debug("Waiting for pod")
+	return Poll(ctx, podPollInterval, podPollTimeout, func() (bool, error) {
+		pod, err := k.Client.CoreV1().Pods(namespace).Get(name, k.DefaultOption)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return pod.Status.Phase == v1.PodRunning, nil
+	})
+}
+
+// WaitForPodsDeleted blocks until no pod matches the selector anymore.
+func (k *Kubernetes) WaitForPodsDeleted(ctx context.Context, namespace string, selector map[string]string) error {
+	methodLogger := logger.WithFields(log.Fields{
+		"method":    "WaitForPodsDeleted",
+		"selector":  selector,
+		"namespace": namespace,
+	})
+	methodLogger.Debug("Waiting for pods to be deleted")
+	return Poll(ctx, podPollInterval, podPollTimeout, func() (bool, error) {
+		pods, err := k.Client.CoreV1().Pods(namespace).List(metav1.ListOptions{
+			LabelSelector: labels.SelectorFromSet(selector).String(),
+		})
+		if err != nil {
+			return false, err
+		}
+		return len(pods.Items) == 0, nil
+	})
+}
@@ -0,0 +1,99 @@
+package kube
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+const (
+	zkutilVolume = "zkutil"
+	zkutilDir    = "/opt/zkutil"
+
+	defaultProbeInitialDelaySeconds = 10
+	defaultProbeTimeoutSeconds      = 5
+)
+
+// addProbes sets the readiness and liveness probes of the ZooKeeper container, the first one
+// of the pod. In the zkutil mode an init container copies zkutil from the probes image into a
+// volume shared with the ZooKeeper container.
+func addProbes(cluster spec.ZookeeperCluster, pod *v1.PodSpec) {
+	probes := cluster.Spec.Probes
+	if probes == nil {
+		probes = &spec.ProbesSpec{}
+	}
+
+	zookeeper := &pod.Containers[0]
+	zookeeper.ReadinessProbe = probe(probes.Mode, "ready", probes.Readiness)
+	zookeeper.LivenessProbe = probe(probes.Mode, "live", probes.Liveness)
+	if probes.Mode != spec.ProbeModeZkutil {
+		return
+	}
+
+	mount := v1.VolumeMount{
+		Name:      zkutilVolume,
+		MountPath: zkutilDir,
+	}
+	zookeeper.VolumeMounts = append(zookeeper.VolumeMounts, mount)
+	pod.InitContainers = append(pod.InitContainers, v1.Container{
+		Name:         "zkutil",
+		Image:        probes.Image,
+		Command:      []string{"zkutil", "install", "-dir", zkutilDir},
+		VolumeMounts: []v1.VolumeMount{mount},
+	})
+	pod.Volumes = append(pod.Volumes, v1.Volume{
+		Name: zkutilVolume,
+		VolumeSource: v1.VolumeSource{
+			EmptyDir: &v1.EmptyDirVolumeSource{},
+		},
+	})
+}
+
+// probe returns the ready or live check of mode with the given timings.
+func probe(mode, check string, timings *spec.ProbeTimings) *v1.Probe {
+	var handler v1.Handler
+	switch mode {
+	case spec.ProbeModeZkutil:
+		handler.Exec = &v1.ExecAction{
+			Command: []string{zkutilDir + "/zkutil", "probe", "-check", check},
+		}
+	case spec.ProbeModeTCP:
+		handler.TCPSocket = &v1.TCPSocketAction{
+			Port: intstr.FromString("client"),
+		}
+	default:
+		handler.Exec = &v1.ExecAction{
+			Command: []string{"zkOk.sh"},
+		}
+	}
+
+	probe := &v1.Probe{
+		Handler:             handler,
+		InitialDelaySeconds: defaultProbeInitialDelaySeconds,
+		TimeoutSeconds:      defaultProbeTimeoutSeconds,
+	}
+	if timings == nil {
+		return probe
+	}
+	if timings.InitialDelaySeconds != 0 {
+		probe.InitialDelaySeconds = timings.InitialDelaySeconds
+	}
+	if timings.TimeoutSeconds != 0 {
+		probe.TimeoutSeconds = timings.TimeoutSeconds
+	}
+	probe.PeriodSeconds = timings.PeriodSeconds
+	probe.FailureThreshold = timings.FailureThreshold
+	return probe
+}
+
+// podManagementPolicy starts the members in parallel with the zkutil probes. They only report a
+// member ready once it has a quorum, which the first member of an ensemble started one after the
+// other never has.
+func podManagementPolicy(cluster spec.ZookeeperCluster) appsv1.PodManagementPolicyType {
+	if cluster.Spec.Probes != nil && cluster.Spec.Probes.Mode == spec.ProbeModeZkutil {
+		return appsv1.ParallelPodManagement
+	}
+	return appsv1.OrderedReadyPodManagement
+}
@@ -0,0 +1,104 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// generateDataVolumeClaims returns the claims the StatefulSet controller creates for
+// the members of the cluster, so they can be provisioned ahead of the StatefulSet.
+func generateDataVolumeClaims(cluster spec.ZookeeperCluster) []*v1.PersistentVolumeClaim {
+	sts := generateZookeeperStatefulset(cluster)
+	template := sts.Spec.VolumeClaimTemplates[0]
+
+	claims := make([]*v1.PersistentVolumeClaim, 0, cluster.Spec.BrokerCount)
+	for i := int32(0); i < cluster.Spec.BrokerCount; i++ {
+		claim := template.DeepCopy()
+		claim.ObjectMeta.Name = dataVolumeClaimName(cluster, int(i))
+		claim.ObjectMeta.Namespace = cluster.ObjectMeta.Namespace
+		claims = append(claims, claim)
+	}
+	return claims
+}
+
+func dataVolumeClaimName(cluster spec.ZookeeperCluster, ordinal int) string {
+	return fmt.Sprintf("zk-data-%s-%d", cluster.ObjectMeta.Name, ordinal)
+}
+
+func (k *Kubernetes) IfPersistentVolumeClaimExists(claim *v1.PersistentVolumeClaim) (bool, error) {
+	methodLogger := logger.WithFields(log.Fields{
+		"method":    "IfPersistentVolumeClaimExists",
+		"name":      claim.ObjectMeta.Name,
+		"namespace": claim.ObjectMeta.Namespace,
+	})
+	_, err := k.Client.CoreV1().PersistentVolumeClaims(claim.ObjectMeta.Namespace).Get(claim.ObjectMeta.Name, k.DefaultOption)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			methodLogger.Debug("PersistentVolumeClaim doesn't exist")
+			return false, nil
+		}
+		methodLogger.WithField("error", err).Error("Cant get PersistentVolumeClaim INFO from API")
+		return false, err
+	}
+	return true, nil
+}
+
+func (k *Kubernetes) CreatePersistentVolumeClaimIfNotExists(claim *v1.PersistentVolumeClaim) error {
+	methodLogger := logger.WithFields(log.Fields{
+		"method":    "CreatePersistentVolumeClaimIfNotExists",
+		"name":      claim.ObjectMeta.Name,
+		"namespace": claim.ObjectMeta.Namespace,
+	})
+	exists, err := k.IfPersistentVolumeClaimExists(claim)
+	if err != nil || exists {
+		return err
+	}
+	_, err = k.Client.CoreV1().PersistentVolumeClaims(claim.ObjectMeta.Namespace).Create(claim)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Error while creating PersistentVolumeClaim")
+	}
+	return err
+}
+
+// DeleteDataVolume deletes the data volume claim of a member and waits until it is gone,
+// so the StatefulSet provisions an empty one when the member starts again.
+func DeleteDataVolume(ctx context.Context, cluster spec.ZookeeperCluster, ordinal int, client Kubernetes) error {
+	methodLogger := logger.WithFields(log.Fields{
+		"method":    "DeleteDataVolume",
+		"cluster":   cluster.ObjectMeta.Name,
+		"namespace": cluster.ObjectMeta.Namespace,
+		"ordinal":   ordinal,
+	})
+	namespace, name := cluster.ObjectMeta.Namespace, dataVolumeClaimName(cluster, ordinal)
+
+	err := client.Client.CoreV1().PersistentVolumeClaims(namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		methodLogger.WithField("error", err).Error("Cant delete PersistentVolumeClaim")
+		return err
+	}
+	return Poll(ctx, podPollInterval, podPollTimeout, func() (bool, error) {
+		_, err := client.Client.CoreV1().PersistentVolumeClaims(namespace).Get(name, client.DefaultOption)
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+}
+
+// DataVolumeExists reports whether the data volume claim of a member exists.
+func DataVolumeExists(cluster spec.ZookeeperCluster, ordinal int, client Kubernetes) (bool, error) {
+	claim := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      dataVolumeClaimName(cluster, ordinal),
+			Namespace: cluster.ObjectMeta.Namespace,
+		},
+	}
+	return client.IfPersistentVolumeClaimExists(claim)
+}
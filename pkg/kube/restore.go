@@ -0,0 +1,171 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	dataVolumeContainer = "data"
+	dataVolumeMountPath = "/var/lib/zookeeper"
+)
+
+// generateDataVolumePod returns an idle pod that mounts the data volume of a
+// member, so its data can be read or replaced while the member is stopped.
+func generateDataVolumePod(cluster spec.ZookeeperCluster, ordinal int) *v1.Pod {
+	zookeeper := generateZookeeperStatefulset(cluster).Spec.Template.Spec.Containers[0]
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-data-%d", cluster.ObjectMeta.Name, ordinal),
+			Namespace: cluster.ObjectMeta.Namespace,
+			Labels:    dataVolumePodLabels(cluster, ordinal),
+		},
+		Spec: v1.PodSpec{
+			RestartPolicy:   v1.RestartPolicyNever,
+			SecurityContext: podSecurityContext(cluster),
+			Containers: []v1.Container{
+				{
+					Name:            dataVolumeContainer,
+					Image:           zookeeper.Image,
+					ImagePullPolicy: zookeeper.ImagePullPolicy,
+					Command:         []string{"sh", "-c", "sleep 3600"},
+					VolumeMounts: []v1.VolumeMount{
+						{
+							Name:      "zk-data",
+							MountPath: dataVolumeMountPath,
+						},
+					},
+					SecurityContext: zookeeper.SecurityContext,
+				},
+			},
+			Volumes: []v1.Volume{
+				{
+					Name: "zk-data",
+					VolumeSource: v1.VolumeSource{
+						PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+							ClaimName: dataVolumeClaimName(cluster, ordinal),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	return pod
+}
+
+func dataVolumePodLabels(cluster spec.ZookeeperCluster, ordinal int) map[string]string {
+	return map[string]string{
+		"app":             "zk-data",
+		"zk-cluster":      cluster.ObjectMeta.Name,
+		"zk-data-ordinal": strconv.Itoa(ordinal),
+	}
+}
+
+// HasData reports whether the cluster already has a StatefulSet or any data volume.
+func HasData(cluster spec.ZookeeperCluster, client Kubernetes) (bool, error) {
+	exists, err := client.IfStatefulSetExists(generateZookeeperStatefulset(cluster))
+	if err != nil || exists {
+		return exists, err
+	}
+	for _, claim := range generateDataVolumeClaims(cluster) {
+		exists, err = client.IfPersistentVolumeClaimExists(claim)
+		if err != nil || exists {
+			return exists, err
+		}
+	}
+	return false, nil
+}
+
+// StopCluster scales the StatefulSet of the cluster to zero and waits until all members are gone.
+func StopCluster(ctx context.Context, cluster spec.ZookeeperCluster, client Kubernetes) error {
+	sts := generateZookeeperStatefulset(cluster)
+	exists, err := client.IfStatefulSetExists(sts)
+	if err != nil || !exists {
+		return err
+	}
+	sts.Spec.Replicas = new(int32)
+	err = client.updateStatefulSet(sts)
+	if err != nil {
+		return err
+	}
+	return client.WaitForPodsDeleted(ctx, cluster.ObjectMeta.Namespace, createLabels(cluster))
+}
+
+// CreateDataVolumes provisions the data volumes of all members ahead of the StatefulSet.
+func CreateDataVolumes(cluster spec.ZookeeperCluster, client Kubernetes) error {
+	for _, claim := range generateDataVolumeClaims(cluster) {
+		err := client.CreatePersistentVolumeClaimIfNotExists(claim)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExecOnDataVolume runs command in a pod that mounts the data volume of a member at
+// /var/lib/zookeeper. The member must not be running.
+func ExecOnDataVolume(ctx context.Context, cluster spec.ZookeeperCluster, ordinal int, command []string, stdin io.Reader, stdout io.Writer, client Kubernetes) error {
+	methodLogger := logger.WithFields(log.Fields{
+		"method":    "ExecOnDataVolume",
+		"cluster":   cluster.ObjectMeta.Name,
+		"namespace": cluster.ObjectMeta.Namespace,
+		"ordinal":   ordinal,
+	})
+
+	pod := generateDataVolumePod(cluster, ordinal)
+	err := client.createPod(pod)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant create data volume pod")
+		return err
+	}
+	defer func() {
+		err := client.deletePod(pod.ObjectMeta.Namespace, pod.ObjectMeta.Name)
+		if err == nil {
+			// the volume has to be released before the member can mount it
+			err = client.WaitForPodsDeleted(ctx, pod.ObjectMeta.Namespace, pod.ObjectMeta.Labels)
+		}
+		if err != nil {
+			methodLogger.WithField("error", err).Error("Cant delete data volume pod")
+		}
+	}()
+
+	err = client.WaitForPodRunning(ctx, pod.ObjectMeta.Namespace, pod.ObjectMeta.Name)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Data volume pod didn't start")
+		return err
+	}
+
+	return client.Exec(pod.ObjectMeta.Namespace, pod.ObjectMeta.Name, dataVolumeContainer, command, stdin, stdout)
+}
+
+// seedScript replaces the snapshots and transaction logs of a member with those of the archive
+// on stdin. Archives of earlier versions only hold the version-2 dir of the data dir.
+var seedScript = fmt.Sprintf(`cd %s && rm -rf data/version-2 log/version-2 version-2 && mkdir -p data log && tar xzf - &&
+if [ -d version-2 ]; then mv version-2 data/; fi`, dataVolumeMountPath)
+
+// SeedDataVolume replaces the data and the log dir on the data volume of a member with the
+// contents of a tar.gz archive, with paths relative to the volume. The member must not be running.
+func SeedDataVolume(ctx context.Context, cluster spec.ZookeeperCluster, ordinal int, archive io.Reader, client Kubernetes) error {
+	err := ExecOnDataVolume(ctx, cluster, ordinal, []string{"sh", "-c", seedScript}, archive, ioutil.Discard, client)
+	if err != nil {
+		return err
+	}
+	logger.WithFields(log.Fields{
+		"method":    "SeedDataVolume",
+		"cluster":   cluster.ObjectMeta.Name,
+		"namespace": cluster.ObjectMeta.Namespace,
+		"ordinal":   ordinal,
+	}).Info("Seeded data volume")
+	return nil
+}
@@ -0,0 +1,109 @@
+package kube
+
+import (
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func generateUserSecret(user spec.ZookeeperUser, username, password, digest string) *v1.Secret {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      user.GetSecretName(),
+			Namespace: user.ObjectMeta.Namespace,
+			Labels: map[string]string{
+				"app":     "zk",
+				"zk-user": user.ObjectMeta.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: spec.SchemeGroupVersion.String(),
+					Kind:       "ZookeeperUser",
+					Name:       user.ObjectMeta.Name,
+					UID:        user.ObjectMeta.UID,
+				},
+			},
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"username": []byte(username),
+			"password": []byte(password),
+			"digest":   []byte(digest),
+		},
+	}
+
+	return secret
+}
+
+// GetSecret returns the secret or nil if it doesn't exist.
+func (k *Kubernetes) GetSecret(namespace, name string) (*v1.Secret, error) {
+	methodLogger := logger.WithFields(log.Fields{
+		"method":    "GetSecret",
+		"name":      name,
+		"namespace": namespace,
+	})
+	secret, err := k.Client.CoreV1().Secrets(namespace).Get(name, k.DefaultOption)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			methodLogger.Debug("Secret doesn't exist")
+			return nil, nil
+		}
+		methodLogger.WithField("error", err).Error("Cant get Secret INFO from API")
+		return nil, err
+	}
+	return secret, nil
+}
+
+func (k *Kubernetes) CreateOrUpdateSecret(secret *v1.Secret) error {
+	methodLogger := logger.WithFields(log.Fields{
+		"method":    "CreateOrUpdateSecret",
+		"name":      secret.ObjectMeta.Name,
+		"namespace": secret.ObjectMeta.Namespace,
+	})
+
+	existing, err := k.GetSecret(secret.ObjectMeta.Namespace, secret.ObjectMeta.Name)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Error while checking if Secret exists")
+		return err
+	}
+	if existing == nil {
+		err = k.createSecret(secret)
+	} else {
+		err = k.updateSecret(secret)
+	}
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Error while creating or updating Secret")
+	}
+	return err
+}
+
+func (k *Kubernetes) createSecret(secret *v1.Secret) error {
+	_, err := k.Client.CoreV1().Secrets(secret.ObjectMeta.Namespace).Create(secret)
+	return err
+}
+
+func (k *Kubernetes) updateSecret(secret *v1.Secret) error {
+	_, err := k.Client.CoreV1().Secrets(secret.ObjectMeta.Namespace).Update(secret)
+	return err
+}
+
+func (k *Kubernetes) deleteSecret(namespace, name string) error {
+	methodLogger := logger.WithFields(log.Fields{
+		"method":    "deleteSecret",
+		"name":      name,
+		"namespace": namespace,
+	})
+	err := k.Client.CoreV1().Secrets(namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			methodLogger.Debug("Trying to delete but Secret doesn't exist.")
+			return nil
+		}
+		methodLogger.WithField("error", err).Error("Can delete Secret")
+		return err
+	}
+	return nil
+}
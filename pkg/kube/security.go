@@ -0,0 +1,91 @@
+package kube
+
+import (
+	"encoding/json"
+
+	"k8s.io/api/core/v1"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+// zookeeperUser is the uid and gid of the zookeeper user of the k8szk image.
+const zookeeperUser int64 = 1000
+
+// confMount is the configuration directory of ZooKeeper.
+var confMount = v1.VolumeMount{Name: "zk-conf", MountPath: "/opt/zookeeper/conf"}
+
+// writableDirs are the directories ZooKeeper writes to outside of its data volume. They are
+// mounted as empty dirs so the root filesystem can be read-only. zkGenConfig.sh writes the
+// configuration on every start.
+var writableDirs = []v1.VolumeMount{
+	confMount,
+	{Name: "zk-logs", MountPath: "/var/log/zookeeper"},
+	{Name: "tmp", MountPath: "/tmp"},
+}
+
+// podSecurityContext runs the members as the zookeeper user. The fsGroup makes the data volume
+// writable for it, including data written as root by earlier versions of the operator.
+func podSecurityContext(cluster spec.ZookeeperCluster) *v1.PodSecurityContext {
+	user, group, nonRoot := zookeeperUser, zookeeperUser, true
+	securityContext := &v1.PodSecurityContext{
+		RunAsUser:    &user,
+		RunAsGroup:   &group,
+		FSGroup:      &group,
+		RunAsNonRoot: &nonRoot,
+	}
+	if overrides := cluster.Spec.SecurityContext; overrides != nil && overrides.Pod != nil {
+		overrideFields(securityContext, overrides.Pod)
+	}
+	return securityContext
+}
+
+// containerSecurityContext drops all capabilities and privileges of the containers of a member.
+func containerSecurityContext(cluster spec.ZookeeperCluster) *v1.SecurityContext {
+	privileged, escalation, readOnly := false, false, true
+	securityContext := &v1.SecurityContext{
+		Privileged:               &privileged,
+		AllowPrivilegeEscalation: &escalation,
+		ReadOnlyRootFilesystem:   &readOnly,
+		Capabilities: &v1.Capabilities{
+			Drop: []v1.Capability{"ALL"},
+		},
+	}
+	if overrides := cluster.Spec.SecurityContext; overrides != nil && overrides.Container != nil {
+		overrideFields(securityContext, overrides.Container)
+	}
+	return securityContext
+}
+
+// overrideFields sets the fields set in overrides on securityContext. Nested structs are merged
+// field by field, lists are replaced.
+func overrideFields(securityContext, overrides interface{}) {
+	data, err := json.Marshal(overrides)
+	if err != nil {
+		return
+	}
+	// unset fields are omitted and keep their value in securityContext
+	json.Unmarshal(data, securityContext)
+}
+
+// addSecurityContext runs all containers and init containers of a member pod with the security
+// contexts of the cluster and mounts the writable dirs into the ZooKeeper container.
+func addSecurityContext(cluster spec.ZookeeperCluster, pod *v1.PodSpec) {
+	pod.SecurityContext = podSecurityContext(cluster)
+	for i := range pod.Containers {
+		pod.Containers[i].SecurityContext = containerSecurityContext(cluster)
+	}
+	for i := range pod.InitContainers {
+		pod.InitContainers[i].SecurityContext = containerSecurityContext(cluster)
+	}
+
+	zookeeper := &pod.Containers[0]
+	for _, mount := range writableDirs {
+		zookeeper.VolumeMounts = append(zookeeper.VolumeMounts, mount)
+		pod.Volumes = append(pod.Volumes, v1.Volume{
+			Name: mount.Name,
+			VolumeSource: v1.VolumeSource{
+				EmptyDir: &v1.EmptyDirVolumeSource{},
+			},
+		})
+	}
+}
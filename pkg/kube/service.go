@@ -1,12 +1,15 @@
 package kube
 
 import (
+	"fmt"
+
 	"github.com/liwang-pivotal/zookeeper-operator/spec"
 
 	"k8s.io/api/core/v1"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
 )
 
 func generateHeadlessService(cluster spec.ZookeeperCluster) *v1.Service {
@@ -18,6 +21,7 @@ func generateHeadlessService(cluster spec.ZookeeperCluster) *v1.Service {
 			"app": "zk-headless",
 		},
 		Namespace: cluster.ObjectMeta.Namespace,
+		OwnerReferences: clusterOwnerReferences(cluster),
 	}
 
 	service := &v1.Service{
@@ -40,8 +44,15 @@ func generateHeadlessService(cluster spec.ZookeeperCluster) *v1.Service {
 			},
 			ClusterIP: "None",
 			Selector: labelSelectors,
+			PublishNotReadyAddresses: publishNotReadyAddresses(cluster),
 		},
 	}
+	if port, enabled := metricsPort(cluster); enabled {
+		service.Spec.Ports = append(service.Spec.Ports, v1.ServicePort{
+			Name: metricsPortName,
+			Port: port,
+		})
+	}
 
 	return service
 }
@@ -61,8 +72,7 @@ func (k *Kubernetes) CreateOrUpdateService(service *v1.Service) error {
 	if !exists {
 		err = k.createService(service)
 	} else {
-		// Update will cause issue: https://www.timcosta.io/kubernetes-service-invalid-clusterip-or-resourceversion
-		//err = k.updateService(service)
+		err = k.updateService(service)
 	}
 	if err != nil {
 		methodLogger.WithField("error", err).Error("Error while creating or updating service")
@@ -103,9 +113,35 @@ func (k *Kubernetes) createService(service *v1.Service) error {
 	return err
 }
 
+// updateService writes the metadata, ports, selector and DNS publishing of service to the live
+// Service if they differ. The cluster IP and the other fields allocated by the API server are kept.
 func (k *Kubernetes) updateService(service *v1.Service) error {
-	_, err := k.Client.CoreV1().Services(service.ObjectMeta.Namespace).Update(service)
-	return err
+	services := k.Client.CoreV1().Services(service.ObjectMeta.Namespace)
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		live, err := services.Get(service.ObjectMeta.Name, k.DefaultOption)
+		if err != nil {
+			return err
+		}
+		diffs := metaDiff(live.ObjectMeta, service.ObjectMeta)
+		diffs = append(diffs, ownedDiff("spec.ports", live.Spec.Ports, service.Spec.Ports)...)
+		diffs = append(diffs, ownedDiff("spec.selector", live.Spec.Selector, service.Spec.Selector)...)
+		// unlike other flags it is also owned when false, clients must not reach unready members
+		if live.Spec.PublishNotReadyAddresses != service.Spec.PublishNotReadyAddresses {
+			diffs = append(diffs, fmt.Sprintf("spec.publishNotReadyAddresses: %t -> %t", live.Spec.PublishNotReadyAddresses, service.Spec.PublishNotReadyAddresses))
+		}
+		if len(diffs) == 0 {
+			return nil
+		}
+		logUpdate("Service", live.ObjectMeta, diffs)
+
+		updated := live.DeepCopy()
+		mergeMeta(&updated.ObjectMeta, service.ObjectMeta)
+		updated.Spec.Ports = service.Spec.Ports
+		updated.Spec.Selector = service.Spec.Selector
+		updated.Spec.PublishNotReadyAddresses = service.Spec.PublishNotReadyAddresses
+		_, err = services.Update(updated)
+		return err
+	})
 }
 
 func (k *Kubernetes) deleteService(service *v1.Service) error {
@@ -0,0 +1,109 @@
+package kube
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/api/core/v1"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+const (
+	// operatorConfigDir is where zkutil init reads the configuration rendered by the operator from.
+	operatorConfigDir = "/etc/zookeeper-operator"
+
+	defaultStartupTimeout = "5m"
+)
+
+var log4jConfig = `zookeeper.root.logger=INFO, CONSOLE
+log4j.rootLogger=${zookeeper.root.logger}
+log4j.appender.CONSOLE=org.apache.log4j.ConsoleAppender
+log4j.appender.CONSOLE.layout=org.apache.log4j.PatternLayout
+log4j.appender.CONSOLE.layout.ConversionPattern=%d{ISO8601} [myid:%X{myid}] - %-5p [%t:%C{1}@%L] - %m%n
+`
+
+// startupConfig renders the configuration files of the members from the settings of the ConfigMap.
+// zkutil init appends the servers to zoo.cfg.
+func startupConfig(cluster spec.ZookeeperCluster, settings map[string]string) map[string]string {
+	lines := []string{
+		"dataDir=" + dataVolumeMountPath + "/data",
+		"dataLogDir=" + dataVolumeMountPath + "/log",
+		"clientPort=2181",
+		"quorumListenOnAllIPs=true",
+		"tickTime=" + settings["tick"],
+		"initLimit=" + settings["init"],
+		"syncLimit=" + settings["sync"],
+		"maxClientCnxns=" + settings["client.cnxns"],
+		"autopurge.snapRetainCount=" + settings["snap.retain"],
+		"autopurge.purgeInterval=" + settings["purge.interval"],
+	}
+	lines = append(lines, metricsConfig(cluster)...)
+
+	heap := settings["jvm.heap"]
+	return map[string]string{
+		"zoo.cfg":          strings.Join(lines, "\n") + "\n",
+		"java.env":         fmt.Sprintf("export JVMFLAGS=\"-Xmx%s -Xms%s\"\n", heap, heap),
+		"log4j.properties": log4jConfig,
+	}
+}
+
+// addStartup runs zkutil init as the first init container of a member pod. It writes into the
+// data volume and the conf dir of the ZooKeeper container, which addSecurityContext mounts.
+func addStartup(cluster spec.ZookeeperCluster, pod *v1.PodSpec) {
+	startup := cluster.Spec.Startup
+	if startup == nil {
+		return
+	}
+	timeout := startup.Timeout
+	if timeout == "" {
+		timeout = defaultStartupTimeout
+	}
+	command := []string{"zkutil", "init", "-config-dir", operatorConfigDir, "-timeout", timeout}
+	if startup.DynamicConfig {
+		command = append(command, "-dynamic")
+	}
+
+	zookeeper := &pod.Containers[0]
+	zookeeper.Env = append(zookeeper.Env, v1.EnvVar{Name: "ZOO_LOG_DIR", Value: "/var/log/zookeeper"})
+	pod.InitContainers = append([]v1.Container{{
+		Name:    "init",
+		Image:   startup.Image,
+		Command: command,
+		Env: []v1.EnvVar{{
+			Name: "ZK_ENSEMBLE",
+			ValueFrom: &v1.EnvVarSource{
+				ConfigMapKeyRef: &v1.ConfigMapKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{Name: "zk-config"},
+					Key:                  "ensemble",
+				},
+			},
+		}},
+		VolumeMounts: []v1.VolumeMount{
+			{Name: "zk-data", MountPath: dataVolumeMountPath},
+			confMount,
+			{Name: "operator-config", MountPath: operatorConfigDir},
+		},
+	}}, pod.InitContainers...)
+	pod.Volumes = append(pod.Volumes, v1.Volume{
+		Name: "operator-config",
+		VolumeSource: v1.VolumeSource{
+			ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{Name: "zk-config"},
+				Items: []v1.KeyToPath{
+					{Key: "zoo.cfg", Path: "zoo.cfg"},
+					{Key: "java.env", Path: "java.env"},
+					{Key: "log4j.properties", Path: "log4j.properties"},
+				},
+			},
+		},
+	})
+}
+
+// publishNotReadyAddresses reports whether the members have to resolve each other before they are
+// ready. zkutil init waits for the name of its own member, and members checked by the zkutil probes
+// are only ready once they reached their peers. Otherwise clients only get ready members.
+func publishNotReadyAddresses(cluster spec.ZookeeperCluster) bool {
+	probes := cluster.Spec.Probes
+	return cluster.Spec.Startup != nil || (probes != nil && probes.Mode == spec.ProbeModeZkutil)
+}
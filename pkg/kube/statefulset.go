@@ -1,23 +1,34 @@
 package kube
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/liwang-pivotal/zookeeper-operator/spec"
 
 	"k8s.io/api/core/v1"
-	appsv1Beta2 "k8s.io/api/apps/v1beta2"
+	appsv1 "k8s.io/api/apps/v1"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
 )
 
 const (
-	defaultCPU    = "500m"
-	defaultDiskSpace   = "100Mi"
-	defaultMemory = "200Mi"
+	defaultCPU    = spec.DefaultCPU
+	defaultDiskSpace   = spec.DefaultDiskSpace
+	defaultMemory = spec.DefaultMemory
+
+	defaultImage = "gcr.io/google_samples/k8szk:v1"
+	defaultStorageClass = "standard"
+	// configFile is written by zkGenConfig.sh
+	configFile = `"${ZK_CONF_DIR:-/opt/zookeeper/conf}/zoo.cfg"`
 )
 
-func generateZookeeperStatefulset(cluster spec.ZookeeperCluster) *appsv1Beta2.StatefulSet {
+func generateZookeeperStatefulset(cluster spec.ZookeeperCluster) *appsv1.StatefulSet {
+	// the webhook defaults the resources, but it may not be deployed
+	spec.SetDefaults(&cluster.Spec)
 
 	name := cluster.ObjectMeta.Name
 	replicas := cluster.Spec.BrokerCount
@@ -37,14 +48,21 @@ func generateZookeeperStatefulset(cluster spec.ZookeeperCluster) *appsv1Beta2.St
 		diskSpace, _ = resource.ParseQuantity(defaultDiskSpace)
 	}
 
-	statefulSet := &appsv1Beta2.StatefulSet{
+	storageClass := cluster.Spec.StorageClass
+	if storageClass == "" {
+		storageClass = defaultStorageClass
+	}
+
+	statefulSet := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: name,
 			Labels: createLabels(cluster),
 			Namespace: cluster.ObjectMeta.Namespace,
+			OwnerReferences: clusterOwnerReferences(cluster),
 		},
-		Spec: appsv1Beta2.StatefulSetSpec{
+		Spec: appsv1.StatefulSetSpec{
 			Replicas: &replicas,
+			PodManagementPolicy: podManagementPolicy(cluster),
 			ServiceName: "zk-headless",
 			Selector: &metav1.LabelSelector{
 				MatchLabels: createLabels(cluster),
@@ -52,9 +70,6 @@ func generateZookeeperStatefulset(cluster spec.ZookeeperCluster) *appsv1Beta2.St
 			Template: v1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: createLabels(cluster),
-					Annotations: map[string]string{
-						"pod.alpha.kubernetes.io/initialized": "true",
-					},
 				},
 				Spec: v1.PodSpec{
 					Affinity: &v1.Affinity{
@@ -77,7 +92,7 @@ func generateZookeeperStatefulset(cluster spec.ZookeeperCluster) *appsv1Beta2.St
 						{
 							Name:  "k8szk",
 							ImagePullPolicy: "Always",
-							Image: "gcr.io/google_samples/k8szk:v1",
+							Image: zookeeperImage(cluster),
 							Ports: []v1.ContainerPort{
 								{
 									Name:          "client",
@@ -193,29 +208,7 @@ func generateZookeeperStatefulset(cluster spec.ZookeeperCluster) *appsv1Beta2.St
 							Command: []string{
 								"sh",
 								"-c",
-								"zkGenConfig.sh && zkServer.sh start-foreground",
-							},
-							ReadinessProbe: &v1.Probe{
-								Handler: v1.Handler{
-									Exec: &v1.ExecAction{
-										Command: []string{
-											"zkOk.sh",
-										},
-									},
-								},
-								InitialDelaySeconds: 10,
-								TimeoutSeconds:      5,
-							},
-							LivenessProbe: &v1.Probe{
-								Handler: v1.Handler{
-									Exec: &v1.ExecAction{
-										Command: []string{
-											"zkOk.sh",
-										},
-									},
-								},
-								InitialDelaySeconds: 10,
-								TimeoutSeconds:      5,
+								startCommand(cluster),
 							},
 							Resources: v1.ResourceRequirements{
 								Limits: v1.ResourceList{
@@ -233,9 +226,6 @@ func generateZookeeperStatefulset(cluster spec.ZookeeperCluster) *appsv1Beta2.St
 									MountPath: "/var/lib/zookeeper",
 								},
 							},
-							SecurityContext: &v1.SecurityContext{
-								Privileged: &[]bool{true}[0],
-							},
 						},
 					},
 				},
@@ -245,11 +235,9 @@ func generateZookeeperStatefulset(cluster spec.ZookeeperCluster) *appsv1Beta2.St
 					ObjectMeta: metav1.ObjectMeta{
 						Name: "zk-data",
 						Labels: createLabels(cluster),
-						Annotations: map[string]string{
-							"volume.beta.kubernetes.io/storage-class": "standard",
-						},
 					},
 					Spec: v1.PersistentVolumeClaimSpec{
+						StorageClassName: &storageClass,
 						AccessModes: []v1.PersistentVolumeAccessMode{
 							v1.ReadWriteOnce,
 						},
@@ -265,10 +253,37 @@ func generateZookeeperStatefulset(cluster spec.ZookeeperCluster) *appsv1Beta2.St
 		},
 	}
 
+	addMonitoring(cluster, &statefulSet.Spec.Template.Spec)
+	addStartup(cluster, &statefulSet.Spec.Template.Spec)
+	addProbes(cluster, &statefulSet.Spec.Template.Spec)
+	addSecurityContext(cluster, &statefulSet.Spec.Template.Spec)
+
 	return statefulSet;
 }
 
-func (k *Kubernetes) CreateOrUpdateStatefulSet(statefulset *appsv1Beta2.StatefulSet) error {
+// zookeeperImage returns the image of the cluster, which has to provide the scripts of k8szk.
+func zookeeperImage(cluster spec.ZookeeperCluster) string {
+	if cluster.Spec.Image == "" {
+		return defaultImage
+	}
+	return cluster.Spec.Image
+}
+
+// startCommand generates the configuration of a member from its environment, appends the
+// settings the scripts of the image don't know about and starts ZooKeeper in the foreground.
+// With a startup spec zkutil init has written the configuration already.
+func startCommand(cluster spec.ZookeeperCluster) string {
+	if cluster.Spec.Startup != nil {
+		return "zkServer.sh start-foreground"
+	}
+	command := "zkGenConfig.sh && "
+	for _, line := range metricsConfig(cluster) {
+		command += fmt.Sprintf("echo %s >> %s && ", line, configFile)
+	}
+	return command + "zkServer.sh start-foreground"
+}
+
+func (k *Kubernetes) CreateOrUpdateStatefulSet(statefulset *appsv1.StatefulSet) error {
 	methodLogger := logger.WithFields(log.Fields{
 		"method":    "CreateOrUpdateStatefulSet",
 		"name":      statefulset.ObjectMeta.Name,
@@ -292,14 +307,14 @@ func (k *Kubernetes) CreateOrUpdateStatefulSet(statefulset *appsv1Beta2.Stateful
 	return err
 }
 
-func (k *Kubernetes) IfStatefulSetExists(statefulset *appsv1Beta2.StatefulSet) (bool, error) {
+func (k *Kubernetes) IfStatefulSetExists(statefulset *appsv1.StatefulSet) (bool, error) {
 	methodLogger := logger.WithFields(log.Fields{
 		"method":    "IfStatefulSetExists",
 		"name":      statefulset.ObjectMeta.Name,
 		"namespace": statefulset.ObjectMeta.Namespace,
 	})
 	namespace := statefulset.ObjectMeta.Namespace
-	sts, err := k.Client.AppsV1beta2().StatefulSets(namespace).Get(statefulset.ObjectMeta.Name, k.DefaultOption)
+	sts, err := k.statefulSets(namespace).Get(statefulset.ObjectMeta.Name, k.DefaultOption)
 
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -320,17 +335,84 @@ func (k *Kubernetes) IfStatefulSetExists(statefulset *appsv1Beta2.StatefulSet) (
 	return true, nil
 }
 
-func (k *Kubernetes) createStatefulSet(statefulset *appsv1Beta2.StatefulSet) error {
-	_, err := k.Client.AppsV1beta2().StatefulSets(statefulset.ObjectMeta.Namespace).Create(statefulset)
-	return err
+// getStatefulSet returns the current version of statefulset, or nil if it doesn't exist.
+func (k *Kubernetes) getStatefulSet(statefulset *appsv1.StatefulSet) (*appsv1.StatefulSet, error) {
+	current, err := k.statefulSets(statefulset.ObjectMeta.Namespace).Get(statefulset.ObjectMeta.Name, k.DefaultOption)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	return current, err
 }
 
-func (k *Kubernetes) updateStatefulSet(statefulset *appsv1Beta2.StatefulSet) error {
-	_, err := k.Client.AppsV1beta2().StatefulSets(statefulset.ObjectMeta.Namespace).Update(statefulset)
+func (k *Kubernetes) createStatefulSet(statefulset *appsv1.StatefulSet) error {
+	_, err := k.statefulSets(statefulset.ObjectMeta.Namespace).Create(statefulset)
 	return err
 }
 
-func (k *Kubernetes) deleteStatefulset(statefulset *appsv1Beta2.StatefulSet) error {
+// updateStatefulSet writes the metadata, replicas and pod template of statefulset to the live
+// StatefulSet if they differ and keeps the fields set by others.
+func (k *Kubernetes) updateStatefulSet(statefulset *appsv1.StatefulSet) error {
+	statefulSets := k.statefulSets(statefulset.ObjectMeta.Namespace)
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		live, err := statefulSets.Get(statefulset.ObjectMeta.Name, k.DefaultOption)
+		if err != nil {
+			return err
+		}
+		diffs := metaDiff(live.ObjectMeta, statefulset.ObjectMeta)
+		diffs = append(diffs, ownedDiff("spec.replicas", live.Spec.Replicas, statefulset.Spec.Replicas)...)
+		diffs = append(diffs, ownedDiff("spec.template", live.Spec.Template, statefulset.Spec.Template)...)
+		if len(diffs) == 0 {
+			return nil
+		}
+		logUpdate("StatefulSet", live.ObjectMeta, diffs)
+
+		updated := live.DeepCopy()
+		mergeMeta(&updated.ObjectMeta, statefulset.ObjectMeta)
+		updated.Spec.Replicas = statefulset.Spec.Replicas
+		// the template is replaced, only labels and annotations others added to it are kept
+		template := statefulset.Spec.Template.DeepCopy()
+		template.Labels = mergeStrings(live.Spec.Template.Labels, template.Labels)
+		template.Annotations = mergeStrings(live.Spec.Template.Annotations, template.Annotations)
+		updated.Spec.Template = *template
+		_, err = statefulSets.Update(updated)
+		return err
+	})
+}
+
+// replaceStatefulSet deletes live if its pod management policy differs from the one of statefulset,
+// which can't be updated. The pods are orphaned and adopted by the StatefulSet created next.
+func (k *Kubernetes) replaceStatefulSet(live, statefulset *appsv1.StatefulSet) error {
+	policy := live.Spec.PodManagementPolicy
+	if policy == "" {
+		policy = appsv1.OrderedReadyPodManagement
+	}
+	if policy == statefulset.Spec.PodManagementPolicy {
+		return nil
+	}
+	logger.WithFields(log.Fields{
+		"method":    "replaceStatefulSet",
+		"name":      live.ObjectMeta.Name,
+		"namespace": live.ObjectMeta.Namespace,
+		"diff":      fmt.Sprintf("spec.podManagementPolicy: %s -> %s", policy, statefulset.Spec.PodManagementPolicy),
+	}).Info("Replacing StatefulSet, keeping its pods")
+	orphan := metav1.DeletePropagationOrphan
+	err := k.statefulSets(live.ObjectMeta.Namespace).Delete(live.ObjectMeta.Name, &metav1.DeleteOptions{
+		PropagationPolicy: &orphan,
+	})
+	if err != nil {
+		return err
+	}
+	// the orphan finalizer keeps the StatefulSet until the garbage collector released its pods
+	return Poll(context.Background(), podPollInterval, podPollTimeout, func() (bool, error) {
+		_, err := k.statefulSets(live.ObjectMeta.Namespace).Get(live.ObjectMeta.Name, k.DefaultOption)
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+}
+
+func (k *Kubernetes) deleteStatefulset(statefulset *appsv1.StatefulSet) error {
 	methodLogger := logger.WithFields(log.Fields{
 		"method":    "DeleteStatefulset",
 		"name":      statefulset.ObjectMeta.Name,
@@ -346,22 +428,22 @@ func (k *Kubernetes) deleteStatefulset(statefulset *appsv1Beta2.StatefulSet) err
 		statefulset.Spec.Replicas = new(int32)
 		err = k.updateStatefulSet(statefulset)
 		if err != nil {
-			methodLogger.WithField("error", err).Error("Could not scale statefulset: %s", statefulset.Name)
+			methodLogger.WithField("error", err).Error("Could not scale statefulset")
 		} else {
-			methodLogger.Info("Scaled statefulset %s to zero: ", statefulset.Name)
+			methodLogger.Info("Scaled statefulset to zero")
 		}
 
-		err := k.Client.AppsV1beta1().StatefulSets(statefulset.ObjectMeta.Namespace).Delete(statefulset.ObjectMeta.Name, &metav1.DeleteOptions{
+		err := k.statefulSets(statefulset.ObjectMeta.Namespace).Delete(statefulset.ObjectMeta.Name, &metav1.DeleteOptions{
 			PropagationPolicy: func() *metav1.DeletionPropagation {
 				foreground := metav1.DeletePropagationForeground
 				return &foreground
 			}(),
 		})
 		if err != nil {
-			methodLogger.WithField("error", err).Error("Could not delete statefulset: %s", statefulset.Name)
+			methodLogger.WithField("error", err).Error("Could not delete statefulset")
 			return err
 		} else {
-			methodLogger.Info("Deleting statefulset: ", statefulset.Name)
+			methodLogger.Info("Deleting statefulset")
 		}
 	} else {
 		methodLogger.Debug("Trying to delete but StatefulSet doesn't exist.")
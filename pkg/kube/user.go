@@ -0,0 +1,55 @@
+package kube
+
+import (
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/zookeeper"
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+// UserCredentials are the credentials stored in the Secret of a ZookeeperUser.
+type UserCredentials struct {
+	Username string
+	Password string
+	Digest   string
+}
+
+// CreateUser makes sure the credentials Secret of the user exists. An existing
+// password is kept, so applications mounting the Secret keep working.
+func CreateUser(user spec.ZookeeperUser, client Kubernetes) (*UserCredentials, error) {
+	username := user.GetUsername()
+
+	existing, err := client.GetSecret(user.ObjectMeta.Namespace, user.GetSecretName())
+	if err != nil {
+		return nil, err
+	}
+
+	var password string
+	if existing != nil && len(existing.Data["password"]) > 0 {
+		password = string(existing.Data["password"])
+	} else {
+		password, err = zookeeper.GeneratePassword()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	credentials := &UserCredentials{
+		Username: username,
+		Password: password,
+		Digest:   zookeeper.Digest(username, password),
+	}
+
+	secret := generateUserSecret(user, credentials.Username, credentials.Password, credentials.Digest)
+	if existing != nil {
+		secret.ObjectMeta.ResourceVersion = existing.ObjectMeta.ResourceVersion
+	}
+	err = client.CreateOrUpdateSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+func DeleteUser(user spec.ZookeeperUser, client Kubernetes) error {
+	return client.deleteSecret(user.ObjectMeta.Namespace, user.GetSecretName())
+}
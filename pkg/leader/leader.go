@@ -0,0 +1,135 @@
+package leader
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
+)
+
+var (
+	logger = log.WithFields(log.Fields{
+		"package": "leader",
+	})
+)
+
+// Config configures the election between the operator replicas.
+type Config struct {
+	// Namespace and Name of the lock object.
+	Namespace string
+	Name      string
+	// LockType is the kind of the lock object, configmaps, endpoints or leases.
+	LockType string
+	// Identity of this replica, usually the pod name.
+	Identity string
+
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+}
+
+// Elector campaigns for leadership and runs the reconcile loops only while leading.
+type Elector struct {
+	config  Config
+	lock    resourcelock.Interface
+	elector *leaderelection.LeaderElector
+	run     func(ctx context.Context)
+	ctx     context.Context
+
+	mutex   sync.Mutex
+	leading bool
+}
+
+// New creates an elector that calls run once it acquired the lock.
+func New(config Config, client kube.Kubernetes, recorder record.EventRecorder, run func(ctx context.Context)) (*Elector, error) {
+	lock, err := resourcelock.New(config.LockType, config.Namespace, config.Name,
+		client.Client.CoreV1(), client.Client.CoordinationV1(),
+		resourcelock.ResourceLockConfig{
+			Identity:      config.Identity,
+			EventRecorder: recorder,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Elector{
+		config: config,
+		lock:   lock,
+		run:    run,
+	}
+	e.elector, err = leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: config.LeaseDuration,
+		RenewDeadline: config.RenewDeadline,
+		RetryPeriod:   config.RetryPeriod,
+		// hand the lock over on shutdown instead of letting the others wait for the lease to expire
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: e.startedLeading,
+			OnStoppedLeading: e.stoppedLeading,
+			OnNewLeader: func(identity string) {
+				logger.WithField("leader", identity).Info("New leader elected")
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Run campaigns for leadership and blocks until it is lost or ctx is done, in which
+// case the lock is released.
+func (e *Elector) Run(ctx context.Context) {
+	logger.WithFields(log.Fields{
+		"identity": e.config.Identity,
+		"lock":     e.lock.Describe(),
+	}).Info("Campaigning for leadership")
+	e.ctx = ctx
+	e.elector.Run(ctx)
+}
+
+func (e *Elector) startedLeading(ctx context.Context) {
+	e.mutex.Lock()
+	e.leading = true
+	e.mutex.Unlock()
+
+	logger.WithField("identity", e.config.Identity).Info("Started leading")
+	e.run(ctx)
+}
+
+// stoppedLeading exits when leadership was lost unexpectedly, as the reconcile loops
+// would otherwise race with the new leader.
+func (e *Elector) stoppedLeading() {
+	e.mutex.Lock()
+	e.leading = false
+	e.mutex.Unlock()
+
+	if e.ctx.Err() == nil {
+		logger.WithField("identity", e.config.Identity).Fatal("Lost leadership, exiting")
+	}
+	logger.WithField("identity", e.config.Identity).Info("Released leadership")
+}
+
+// IsLeader reports whether this replica currently holds the lock.
+func (e *Elector) IsLeader() bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.leading
+}
+
+// Identity of this replica in the election.
+func (e *Elector) Identity() string {
+	return e.config.Identity
+}
+
+// Leader returns the identity of the current leader, empty if unknown.
+func (e *Elector) Leader() string {
+	return e.elector.GetLeader()
+}
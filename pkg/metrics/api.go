@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+)
+
+var verbs = map[string]string{
+	http.MethodGet:    "get",
+	http.MethodPost:   "create",
+	http.MethodPut:    "update",
+	http.MethodPatch:  "patch",
+	http.MethodDelete: "delete",
+}
+
+// InstrumentTransport counts the failed requests sent through rt, to be set as WrapTransport
+// of a rest.Config. NotFound is not counted as a failure: the operator checks whether objects
+// exist by getting them.
+func InstrumentTransport(rt http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		response, err := rt.RoundTrip(request)
+		if err != nil || (response.StatusCode >= 400 && response.StatusCode != http.StatusNotFound) {
+			verb, ok := verbs[request.Method]
+			if !ok {
+				verb = strings.ToLower(request.Method)
+			}
+			apiErrors.WithLabelValues(verb, resource(request.URL.Path)).Inc()
+		}
+		return response, err
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// resource extracts the resource from an API path like /api/v1/namespaces/default/pods/zk-0/exec
+// or /apis/pivotal.io/v1/zookeeperclusters, including the subresource.
+func resource(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(parts) >= 2 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) >= 3 && parts[0] == "apis":
+		parts = parts[3:]
+	default:
+		return "unknown"
+	}
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		parts = parts[2:]
+	}
+	switch len(parts) {
+	case 0:
+		return "unknown"
+	case 1, 2:
+		return parts[0]
+	default:
+		return parts[0] + "/" + parts[2]
+	}
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/samuel/go-zookeeper/zk"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/zookeeper"
+)
+
+var (
+	membersUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ensemble_members_up",
+		Help:      "Number of members of the ensemble answering the srvr command.",
+	}, []string{"namespace", "cluster"})
+
+	leaderPresent = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ensemble_leader_present",
+		Help:      "Whether a member of the ensemble reports to be the leader or standalone.",
+	}, []string{"namespace", "cluster"})
+
+	memberZxid = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ensemble_member_zxid",
+		Help:      "Last zxid seen by the member.",
+	}, []string{"namespace", "cluster", "member"})
+
+	memberAvgLatency = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ensemble_member_avg_latency_milliseconds",
+		Help:      "Average request latency reported by the member.",
+	}, []string{"namespace", "cluster", "member"})
+
+	memberOutstanding = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ensemble_member_outstanding_requests",
+		Help:      "Number of queued requests reported by the member.",
+	}, []string{"namespace", "cluster", "member"})
+
+	// observed is the number of members last observed per cluster, to remove the series of
+	// members that are gone
+	observedMutex sync.Mutex
+	observed      = make(map[[2]string]int)
+)
+
+func registerEnsembleMetrics() {
+	prometheus.MustRegister(
+		membersUp,
+		leaderPresent,
+		memberZxid,
+		memberAvgLatency,
+		memberOutstanding,
+	)
+}
+
+// ObserveEnsemble updates the ensemble gauges of a cluster from the srvr statistics of its
+// members, indexed by ordinal.
+func ObserveEnsemble(clusterNamespace, cluster string, stats []*zk.ServerStats) {
+	up, leader := 0, 0
+	for ordinal, member := range stats {
+		labels := []string{clusterNamespace, cluster, strconv.Itoa(ordinal)}
+		if member == nil || member.Error != nil {
+			deleteMember(labels)
+			continue
+		}
+		up++
+		if member.Mode == zk.ModeLeader || member.Mode == zk.ModeStandalone {
+			leader = 1
+		}
+		memberZxid.WithLabelValues(labels...).Set(float64(zookeeper.Zxid(member)))
+		memberAvgLatency.WithLabelValues(labels...).Set(float64(member.AvgLatency))
+		memberOutstanding.WithLabelValues(labels...).Set(float64(member.Outstanding))
+	}
+	membersUp.WithLabelValues(clusterNamespace, cluster).Set(float64(up))
+	leaderPresent.WithLabelValues(clusterNamespace, cluster).Set(float64(leader))
+
+	observedMutex.Lock()
+	defer observedMutex.Unlock()
+	key := [2]string{clusterNamespace, cluster}
+	for ordinal := len(stats); ordinal < observed[key]; ordinal++ {
+		deleteMember([]string{clusterNamespace, cluster, strconv.Itoa(ordinal)})
+	}
+	observed[key] = len(stats)
+}
+
+// RetainEnsembles removes the series of all observed clusters for which retain returns false.
+func RetainEnsembles(retain func(clusterNamespace, cluster string) bool) {
+	observedMutex.Lock()
+	defer observedMutex.Unlock()
+	for key, members := range observed {
+		clusterNamespace, cluster := key[0], key[1]
+		if retain(clusterNamespace, cluster) {
+			continue
+		}
+		for ordinal := 0; ordinal < members; ordinal++ {
+			deleteMember([]string{clusterNamespace, cluster, strconv.Itoa(ordinal)})
+		}
+		membersUp.DeleteLabelValues(clusterNamespace, cluster)
+		leaderPresent.DeleteLabelValues(clusterNamespace, cluster)
+		delete(observed, key)
+	}
+}
+
+func deleteMember(labels []string) {
+	memberZxid.DeleteLabelValues(labels...)
+	memberAvgLatency.DeleteLabelValues(labels...)
+	memberOutstanding.DeleteLabelValues(labels...)
+}
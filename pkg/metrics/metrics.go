@@ -0,0 +1,70 @@
+// Package metrics defines the Prometheus metrics of the operator, served by main on -metric-path.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "zookeeper_operator"
+
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+var (
+	reconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconcile_total",
+		Help:      "Number of processed ZookeeperCluster events by cluster and result.",
+	}, []string{"namespace", "cluster", "result"})
+
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Time taken to process a ZookeeperCluster event by cluster and result.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	}, []string{"namespace", "cluster", "result"})
+
+	queueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "queue_depth",
+		Help:      "Number of events waiting to be processed by queue.",
+	}, []string{"queue"})
+
+	queueLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "queue_latency_seconds",
+		Help:      "Time events waited in the queue before being processed.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10),
+	}, []string{"queue"})
+
+	apiErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "api_errors_total",
+		Help:      "Number of failed Kubernetes API requests by verb and resource.",
+	}, []string{"verb", "resource"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		reconcileTotal,
+		reconcileDuration,
+		queueDepth,
+		queueLatency,
+		apiErrors,
+	)
+	registerEnsembleMetrics()
+}
+
+// ObserveReconcile counts a processed cluster event that started at start and failed with err, if any.
+func ObserveReconcile(clusterNamespace, cluster string, start time.Time, err error) {
+	result := ResultSuccess
+	if err != nil {
+		result = ResultError
+	}
+	reconcileTotal.WithLabelValues(clusterNamespace, cluster, result).Inc()
+	reconcileDuration.WithLabelValues(clusterNamespace, cluster, result).Observe(time.Since(start).Seconds())
+}
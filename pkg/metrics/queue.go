@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// Queues of the events sent from the informers to the processor.
+var (
+	ClusterQueue = NewQueue("clusters")
+	UserQueue    = NewQueue("users")
+	BackupQueue  = NewQueue("backups")
+)
+
+// Queue tracks the depth and latency of an event channel. The channel is FIFO, so the
+// enqueue times are kept in the same order: Add is called before every send, Done after
+// every receive.
+type Queue struct {
+	name   string
+	mutex  sync.Mutex
+	queued []time.Time
+}
+
+func NewQueue(name string) *Queue {
+	queueDepth.WithLabelValues(name).Set(0)
+	return &Queue{name: name}
+}
+
+// Add records an event about to be sent on the channel.
+func (q *Queue) Add() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.queued = append(q.queued, time.Now())
+	queueDepth.WithLabelValues(q.name).Set(float64(len(q.queued)))
+}
+
+// Done records the oldest event as received from the channel.
+func (q *Queue) Done() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if len(q.queued) == 0 {
+		return
+	}
+	queueLatency.WithLabelValues(q.name).Observe(time.Since(q.queued[0]).Seconds())
+	q.queued = q.queued[1:]
+	queueDepth.WithLabelValues(q.name).Set(float64(len(q.queued)))
+}
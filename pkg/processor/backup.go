@@ -0,0 +1,258 @@
+package processor
+
+import (
+	"time"
+
+	"github.com/robfig/cron"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/backup"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+type backupSchedule struct {
+	schedule string
+	stop     chan struct{}
+}
+
+func (p *Processor) processBackupEvent(currentEvent spec.ZookeeperBackupWatchEvent) {
+	methodLogger := log.WithFields(log.Fields{
+		"method":                   "processBackupEvent",
+		"backupName":               currentEvent.Object.Name,
+		"ZookeeperBackupEventType": currentEvent.Type,
+	})
+	methodLogger.Info("Caught new backup event: ", currentEvent.Type)
+	switch {
+	case currentEvent.Type == "ADDED" || currentEvent.Type == "UPDATED":
+		p.processZookeeperBackup(currentEvent.Object)
+
+	case currentEvent.Type == "DELETED":
+		p.stopBackupSchedule(backupKey(currentEvent.Object))
+	}
+}
+
+func (p *Processor) processZookeeperBackup(backupSpec spec.ZookeeperBackup) {
+	methodLogger := log.WithFields(log.Fields{
+		"method":     "processZookeeperBackup",
+		"backupName": backupSpec.ObjectMeta.Name,
+		"namespace":  backupSpec.ObjectMeta.Namespace,
+	})
+	key := backupKey(backupSpec)
+
+	if backupSpec.Spec.Schedule == "" {
+		p.stopBackupSchedule(key)
+		if backupSpec.State.Phase == "" {
+			namespace, name := backupSpec.ObjectMeta.Namespace, backupSpec.ObjectMeta.Name
+			p.goWork(func() { p.runBackup(namespace, name) })
+		}
+		return
+	}
+
+	p.backupsMutex.Lock()
+	current, scheduled := p.backupSchedules[key]
+	p.backupsMutex.Unlock()
+	if scheduled && current.schedule == backupSpec.Spec.Schedule {
+		return
+	}
+
+	schedule, err := cron.ParseStandard(backupSpec.Spec.Schedule)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Invalid backup schedule")
+		p.updateBackupState(backupSpec.ObjectMeta.Namespace, backupSpec.ObjectMeta.Name, func(state *spec.ZookeeperBackupState) {
+			state.Phase = spec.BackupPhaseFailed
+			state.Message = "invalid schedule: " + err.Error()
+		})
+		return
+	}
+
+	p.stopBackupSchedule(key)
+	stop := make(chan struct{})
+	p.backupsMutex.Lock()
+	p.backupSchedules[key] = &backupSchedule{schedule: backupSpec.Spec.Schedule, stop: stop}
+	p.backupsMutex.Unlock()
+
+	namespace, name := backupSpec.ObjectMeta.Namespace, backupSpec.ObjectMeta.Name
+	go func() {
+		for {
+			now := time.Now()
+			select {
+			case <-time.After(schedule.Next(now).Sub(now)):
+				p.goWork(func() { p.runBackup(namespace, name) })
+			case <-stop:
+				return
+			case <-p.done:
+				return
+			}
+		}
+	}()
+
+	methodLogger.WithField("schedule", backupSpec.Spec.Schedule).Info("Scheduled backup")
+	if backupSpec.State.Phase != spec.BackupPhaseScheduled {
+		p.updateBackupState(namespace, name, func(state *spec.ZookeeperBackupState) {
+			state.Phase = spec.BackupPhaseScheduled
+			state.Message = ""
+		})
+	}
+}
+
+// runBackup takes a single backup and records it in the state of the ZookeeperBackup.
+func (p *Processor) runBackup(namespace, name string) {
+	methodLogger := log.WithFields(log.Fields{
+		"method":     "runBackup",
+		"backupName": name,
+		"namespace":  namespace,
+	})
+	key := namespace + "/" + name
+
+	p.backupsMutex.Lock()
+	if p.backupsRunning[key] {
+		p.backupsMutex.Unlock()
+		methodLogger.Warn("Backup is already running, skipping")
+		return
+	}
+	p.backupsRunning[key] = true
+	p.backupsMutex.Unlock()
+	defer func() {
+		p.backupsMutex.Lock()
+		delete(p.backupsRunning, key)
+		p.backupsMutex.Unlock()
+	}()
+
+	backupSpec, err := p.crdController.GetZookeeperBackup(namespace, name)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant get backup")
+		return
+	}
+	finalPhase := spec.BackupPhaseCompleted
+	if backupSpec.Spec.Schedule != "" {
+		finalPhase = spec.BackupPhaseScheduled
+	}
+
+	p.updateBackupState(namespace, name, func(state *spec.ZookeeperBackupState) {
+		state.Phase = spec.BackupPhaseRunning
+	})
+
+	record, kept, err := p.takeBackup(*backupSpec)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Backup failed")
+		p.updateBackupState(namespace, name, func(state *spec.ZookeeperBackupState) {
+			// a failed run doesn't stop the schedule
+			state.Phase = spec.BackupPhaseFailed
+			if backupSpec.Spec.Schedule != "" {
+				state.Phase = spec.BackupPhaseScheduled
+			}
+			state.Message = err.Error()
+		})
+		return
+	}
+
+	p.updateBackupState(namespace, name, func(state *spec.ZookeeperBackupState) {
+		retained := make(map[string]bool, len(kept))
+		for _, archive := range kept {
+			retained[archive] = true
+		}
+		var backups []spec.BackupRecord
+		for _, previous := range append(state.Backups, *record) {
+			if retained[previous.Name] {
+				backups = append(backups, previous)
+			}
+		}
+		state.Backups = backups
+		state.Phase = finalPhase
+		state.LastBackupTime = &record.Time
+		state.Message = ""
+	})
+}
+
+// takeBackup stores a backup of the cluster and prunes the archives beyond the retention.
+// The outcome is recorded as an event on the cluster.
+func (p *Processor) takeBackup(backupSpec spec.ZookeeperBackup) (record *spec.BackupRecord, kept []string, err error) {
+	namespace := backupSpec.ObjectMeta.Namespace
+
+	cluster, err := p.crdController.GetZookeeperCluster(namespace, backupSpec.Spec.ClusterName)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err != nil {
+			p.recorder.Eventf(cluster, v1.EventTypeWarning, kube.EventReasonBackupFailed,
+				"Backup %s failed: %v", backupSpec.ObjectMeta.Name, err)
+			return
+		}
+		p.recorder.Eventf(cluster, v1.EventTypeNormal, kube.EventReasonBackupSucceeded,
+			"Backup %s stored archive %s at zxid %s", backupSpec.ObjectMeta.Name, record.Name, record.Zxid)
+	}()
+
+	store, err := p.backupStore(backupSpec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	record, err = backup.Run(*cluster, p.kube, store)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	kept, err = backup.Prune(store, backupSpec.Spec.Retention)
+	if err != nil {
+		return nil, nil, err
+	}
+	return record, kept, nil
+}
+
+// backupStore opens the storage of a ZookeeperBackup. Archives are kept below <namespace>/<name>.
+func (p *Processor) backupStore(backupSpec spec.ZookeeperBackup) (backup.Store, error) {
+	namespace := backupSpec.ObjectMeta.Namespace
+
+	var credentials map[string][]byte
+	if backupSpec.Spec.Storage.S3 != nil {
+		secret, err := p.kube.GetSecret(namespace, backupSpec.Spec.Storage.S3.CredentialsSecret)
+		if err != nil {
+			return nil, err
+		}
+		if secret != nil {
+			credentials = secret.Data
+		}
+	}
+
+	return backup.NewStore(backupSpec.Spec.Storage, namespace+"/"+backupSpec.ObjectMeta.Name, credentials)
+}
+
+func (p *Processor) stopBackupSchedule(key string) {
+	p.backupsMutex.Lock()
+	defer p.backupsMutex.Unlock()
+	if current, ok := p.backupSchedules[key]; ok {
+		close(current.stop)
+		delete(p.backupSchedules, key)
+	}
+}
+
+// updateBackupState applies update to the latest version of the backup and writes it back.
+func (p *Processor) updateBackupState(namespace, name string, update func(state *spec.ZookeeperBackupState)) {
+	methodLogger := log.WithFields(log.Fields{
+		"method":     "updateBackupState",
+		"backupName": name,
+		"namespace":  namespace,
+	})
+
+	// the cached backup may lag behind the API server, a conflict is retried on a newer copy
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		backupSpec, err := p.crdController.GetZookeeperBackup(namespace, name)
+		if err != nil {
+			return err
+		}
+		update(&backupSpec.State)
+		_, err = p.crdController.UpdateZookeeperBackup(backupSpec)
+		return err
+	})
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant update backup state")
+	}
+}
+
+func backupKey(backupSpec spec.ZookeeperBackup) string {
+	return backupSpec.ObjectMeta.Namespace + "/" + backupSpec.ObjectMeta.Name
+}
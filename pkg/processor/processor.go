@@ -1,57 +1,139 @@
 package processor
 
 import (
-	log "github.com/sirupsen/logrus"
-	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
 	"github.com/liwang-pivotal/zookeeper-operator/pkg/controller"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/metrics"
 	"github.com/liwang-pivotal/zookeeper-operator/spec"
+	log "github.com/sirupsen/logrus"
 )
 
 type Processor struct {
-	baseBrokerImage    string
-	crdController      controller.CustomResourceController
-	watchEventsChannel chan spec.ZookeeperClusterWatchEvent
-	control            chan int
-	errors             chan error
-	kube               kube.Kubernetes
+	baseBrokerImage     string
+	crdController       controller.Interface
+	watchEventsChannel  chan spec.ZookeeperClusterWatchEvent
+	userEventsChannel   chan spec.ZookeeperUserWatchEvent
+	backupEventsChannel chan spec.ZookeeperBackupWatchEvent
+	zookeeperAuth       string
+	errors              chan error
+	kube                kube.Kubernetes
+	recorder            record.EventRecorder
+
+	backupsMutex    sync.Mutex
+	backupSchedules map[string]*backupSchedule
+	backupsRunning  map[string]bool
+
+	restoresMutex   sync.Mutex
+	restoresRunning map[string]bool
+
+	recoveriesMutex   sync.Mutex
+	recoveriesRunning map[string]bool
+
+	// quorum is whether each cluster had a serving majority at its last check
+	quorumMutex sync.Mutex
+	quorum      map[string]bool
+
+	// done is closed when the processor stops watching events
+	done chan struct{}
+
+	// ctx is handed to restores, recoveries and backups. Unlike the context of Run it is
+	// only cancelled when Shutdown stops waiting for them.
+	ctx        context.Context
+	cancelWork context.CancelFunc
+	workMutex  sync.Mutex
+	stopping   bool
+	work       sync.WaitGroup
+
+	statusMutex sync.Mutex
+	started     bool
+	statuses    map[string]ClusterStatus
 }
 
 func New(image string,
-	crdClient controller.CustomResourceController,
-	control chan int,
-	kube kube.Kubernetes) (*Processor, error){
+	zookeeperAuth string,
+	crdClient controller.Interface,
+	client kube.Kubernetes) (*Processor, error) {
+	ctx, cancelWork := context.WithCancel(context.Background())
 	p := &Processor{
-		baseBrokerImage:    image,
-		watchEventsChannel: make(chan spec.ZookeeperClusterWatchEvent, 100),
-		crdController:      crdClient,
-		control:            control,
-		errors:             make(chan error),
-		kube:               kube,
+		baseBrokerImage:     image,
+		watchEventsChannel:  make(chan spec.ZookeeperClusterWatchEvent, 100),
+		userEventsChannel:   make(chan spec.ZookeeperUserWatchEvent, 100),
+		backupEventsChannel: make(chan spec.ZookeeperBackupWatchEvent, 100),
+		zookeeperAuth:       zookeeperAuth,
+		crdController:       crdClient,
+		errors:              make(chan error),
+		kube:                client,
+		recorder:            client.Recorder,
+		backupSchedules:     make(map[string]*backupSchedule),
+		backupsRunning:      make(map[string]bool),
+		restoresRunning:     make(map[string]bool),
+		recoveriesRunning:   make(map[string]bool),
+		quorum:              make(map[string]bool),
+		done:                make(chan struct{}),
+		statuses:            make(map[string]ClusterStatus),
+		ctx:                 ctx,
+		cancelWork:          cancelWork,
 	}
 	log.Info("Created Processor")
 	return p, nil
 }
 
-func (p *Processor) Run() error {
+// Run starts the informers and processes their events until ctx is done.
+func (p *Processor) Run(ctx context.Context) error {
+	p.workMutex.Lock()
+	defer p.workMutex.Unlock()
+	if p.stopping {
+		return errors.New("processor is shutting down")
+	}
 	log.Info("Running Processor")
-	p.watchEvents()
+	p.statusMutex.Lock()
+	p.started = true
+	p.statusMutex.Unlock()
+	p.watchEvents(ctx)
+	p.watchQuorum()
 	return nil
 }
 
-func (p *Processor) watchEvents() {
+func (p *Processor) watchEvents(ctx context.Context) {
 
-	p.crdController.MonitorZookeeperEvents(p.watchEventsChannel, p.control)
+	p.crdController.MonitorZookeeperEvents(ctx, p.watchEventsChannel)
+	p.crdController.MonitorZookeeperUserEvents(ctx, p.userEventsChannel)
+	p.crdController.MonitorZookeeperBackupEvents(ctx, p.backupEventsChannel)
+	p.crdController.MonitorOwnedResources(ctx, p.watchEventsChannel)
 	log.Info("Watching Events")
 	go func() {
+		defer close(p.done)
+		// the processor reads clusters and backups from the informer caches, which have to be filled first
+		if !cache.WaitForCacheSync(ctx.Done(), p.crdController.HasSynced) {
+			log.Warn("Recieved shutdown signal before the caches synced, stopping event loop")
+			return
+		}
 		for {
 			select {
 			case event := <-p.watchEventsChannel:
+				metrics.ClusterQueue.Done()
 				log.Info("recieved event through event channel: ", event.Type)
 				p.processEvent(event)
+			case event := <-p.userEventsChannel:
+				metrics.UserQueue.Done()
+				log.Info("recieved user event through event channel: ", event.Type)
+				p.processUserEvent(event)
+			case event := <-p.backupEventsChannel:
+				metrics.BackupQueue.Done()
+				log.Info("recieved backup event through event channel: ", event.Type)
+				p.processBackupEvent(event)
 			case err := <-p.errors:
 				log.WithField("error", err).Error("Recieved Error through error channel")
-			case ctl := <-p.control:
-				log.WithField("control-event", ctl).Warn("Recieved Something on Control Channel, shutting down")
+			case <-ctx.Done():
+				log.Warn("Recieved shutdown signal, stopping event loop")
 				return
 			}
 		}
@@ -60,21 +142,48 @@ func (p *Processor) watchEvents() {
 
 func (p *Processor) processEvent(currentEvent spec.ZookeeperClusterWatchEvent) {
 	methodLogger := log.WithFields(log.Fields{
-		"method":                "processEvent",
-		"clusterName":           currentEvent.Object.Name,
+		"method":                    "processEvent",
+		"clusterName":               currentEvent.Object.Name,
 		"ZookeeperClusterEventType": currentEvent.Type,
 	})
 	methodLogger.WithField("event-type", currentEvent.Type).Info("Caught new cluster event: ", currentEvent.Type)
+	start := time.Now()
+	var err error
 	switch {
-	case currentEvent.Type == "ADDED" || currentEvent.Type == "UPDATED":
-		p.processZookeeperCluster(currentEvent.Object)
+	case currentEvent.Type == "ADDED" || currentEvent.Type == "UPDATED" || currentEvent.Type == "RESYNC":
+		err = p.processZookeeperCluster(currentEvent.Object)
 
 	case currentEvent.Type == "DELETED":
-		p.deleteZookeeperCluster(currentEvent.Object)
+		err = p.deleteZookeeperCluster(currentEvent.Object)
+		if err != nil {
+			methodLogger.WithField("error", err).Error("Cant delete zookeeper cluster")
+		}
+	}
+	metrics.ObserveReconcile(currentEvent.Object.ObjectMeta.Namespace, currentEvent.Object.ObjectMeta.Name, start, err)
+	p.recordReconcile(currentEvent, err)
+}
+
+func (p *Processor) processZookeeperCluster(clusterSpec spec.ZookeeperCluster) error {
+	if p.recoveryRunning(clusterSpec) {
+		log.WithField("clusterName", clusterSpec.ObjectMeta.Name).Info("Recovery in progress, skipping event")
+		return nil
+	}
+	if recoveryNeeded(clusterSpec) {
+		p.goWork(func() { p.recoverZookeeperCluster(clusterSpec) })
+		return nil
+	}
+	if p.restoreRunning(clusterSpec) {
+		log.WithField("clusterName", clusterSpec.ObjectMeta.Name).Info("Restore in progress, skipping event")
+		return nil
+	}
+	if restoreNeeded(clusterSpec) {
+		p.goWork(func() { p.restoreZookeeperCluster(clusterSpec) })
+		return nil
 	}
+	return p.createZookeeperCluster(clusterSpec)
 }
 
-func (p *Processor) processZookeeperCluster(clusterSpec spec.ZookeeperCluster) {
+func (p *Processor) createZookeeperCluster(clusterSpec spec.ZookeeperCluster) error {
 	methodLogger := log.WithFields(log.Fields{
 		"method":      "CreateZookeeperCluster",
 		"clusterName": clusterSpec.ObjectMeta.Name,
@@ -82,8 +191,10 @@ func (p *Processor) processZookeeperCluster(clusterSpec spec.ZookeeperCluster) {
 
 	err := kube.CreateCluster(clusterSpec, p.kube)
 	if err != nil {
-		methodLogger.WithField("error", err).Fatal("Cant create zookeeper cluster")
+		// the failure is recorded as an event on the cluster
+		methodLogger.WithField("error", err).Error("Cant create zookeeper cluster")
 	}
+	return err
 }
 
 func (p *Processor) deleteZookeeperCluster(clusterSpec spec.ZookeeperCluster) error {
@@ -0,0 +1,279 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/metrics"
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+// fakeController serves custom resources from memory and hands out the event channels
+// the processor subscribes with.
+type fakeController struct {
+	mutex         sync.Mutex
+	clusters      map[string]spec.ZookeeperCluster
+	clusterEvents chan spec.ZookeeperClusterWatchEvent
+	// users records every update of a ZookeeperUser
+	users []spec.ZookeeperUser
+}
+
+func (c *fakeController) MonitorZookeeperEvents(ctx context.Context, eventsChannel chan spec.ZookeeperClusterWatchEvent) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.clusterEvents = eventsChannel
+}
+
+func (c *fakeController) MonitorZookeeperUserEvents(ctx context.Context, eventsChannel chan spec.ZookeeperUserWatchEvent) {
+}
+
+func (c *fakeController) MonitorZookeeperBackupEvents(ctx context.Context, eventsChannel chan spec.ZookeeperBackupWatchEvent) {
+}
+
+func (c *fakeController) MonitorOwnedResources(ctx context.Context, eventsChannel chan spec.ZookeeperClusterWatchEvent) {
+}
+
+func (c *fakeController) HasSynced() bool { return true }
+
+func (c *fakeController) InformersRunning() error { return nil }
+
+func (c *fakeController) GetZookeeperCluster(namespace, name string) (*spec.ZookeeperCluster, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	cluster, ok := c.clusters[namespace+"/"+name]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Group: spec.SchemeGroupVersion.Group, Resource: spec.CRDRessourcePlural}, name)
+	}
+	return cluster.DeepCopy(), nil
+}
+
+func (c *fakeController) ListZookeeperClusters() ([]spec.ZookeeperCluster, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	var clusters []spec.ZookeeperCluster
+	for _, cluster := range c.clusters {
+		clusters = append(clusters, *cluster.DeepCopy())
+	}
+	return clusters, nil
+}
+
+func (c *fakeController) UpdateZookeeperCluster(cluster *spec.ZookeeperCluster) (*spec.ZookeeperCluster, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.clusters[clusterKey(*cluster)] = *cluster.DeepCopy()
+	return cluster, nil
+}
+
+func (c *fakeController) UpdateZookeeperUser(user *spec.ZookeeperUser) (*spec.ZookeeperUser, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.users = append(c.users, *user.DeepCopy())
+	return user, nil
+}
+
+func (c *fakeController) GetZookeeperBackup(namespace, name string) (*spec.ZookeeperBackup, error) {
+	return nil, apierrors.NewNotFound(schema.GroupResource{Group: spec.SchemeGroupVersion.Group, Resource: spec.CRDBackupRessourcePlural}, name)
+}
+
+func (c *fakeController) UpdateZookeeperBackup(backup *spec.ZookeeperBackup) (*spec.ZookeeperBackup, error) {
+	return backup, nil
+}
+
+func newTestProcessor(t *testing.T) (*Processor, *fakeController, *fake.Clientset) {
+	clientset := fake.NewSimpleClientset()
+	// the Prometheus Operator is not installed
+	clientset.Resources = []*metav1.APIResourceList{
+		{GroupVersion: kube.AppsV1, APIResources: []metav1.APIResource{{Name: "statefulsets", Namespaced: true, Kind: "StatefulSet"}}},
+		{GroupVersion: "monitoring.coreos.com/v1"},
+	}
+	client := kube.Kubernetes{
+		Client:   clientset,
+		Recorder: record.NewFakeRecorder(100),
+	}
+	controller := &fakeController{clusters: make(map[string]spec.ZookeeperCluster)}
+	p, err := New("", "", controller, client)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return p, controller, clientset
+}
+
+func testCluster() spec.ZookeeperCluster {
+	return spec.ZookeeperCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "zk", Namespace: "test"},
+		Spec:       spec.ZookeeperClusterSpec{BrokerCount: 3},
+	}
+}
+
+func TestProcessEvent(t *testing.T) {
+	p, _, clientset := newTestProcessor(t)
+	cluster := testCluster()
+
+	p.processEvent(spec.ZookeeperClusterWatchEvent{Type: "ADDED", Object: cluster})
+	sts, err := clientset.AppsV1().StatefulSets("test").Get("zk", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("ADDED didn't create the StatefulSet: %v", err)
+	}
+	if *sts.Spec.Replicas != 3 {
+		t.Errorf("StatefulSet has %d replicas, want 3", *sts.Spec.Replicas)
+	}
+	clusters := p.Clusters()
+	if len(clusters) != 1 || clusters[0].Result != metrics.ResultSuccess || clusters[0].LastEvent != "ADDED" {
+		t.Errorf("status after ADDED is %+v", clusters)
+	}
+
+	updated := cluster
+	updated.Spec.BrokerCount = 5
+	p.processEvent(spec.ZookeeperClusterWatchEvent{Type: "UPDATED", Object: updated, OldObject: cluster})
+	sts, _ = clientset.AppsV1().StatefulSets("test").Get("zk", metav1.GetOptions{})
+	if *sts.Spec.Replicas != 5 {
+		t.Errorf("UPDATED left the StatefulSet at %d replicas, want 5", *sts.Spec.Replicas)
+	}
+	if clusters := p.Clusters(); len(clusters) != 1 || clusters[0].BrokerCount != 5 {
+		t.Errorf("status after UPDATED is %+v", clusters)
+	}
+
+	p.processEvent(spec.ZookeeperClusterWatchEvent{Type: "DELETED", Object: updated})
+	if _, err := clientset.AppsV1().StatefulSets("test").Get("zk", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("DELETED left the StatefulSet: %v", err)
+	}
+	if clusters := p.Clusters(); len(clusters) != 0 {
+		t.Errorf("status of a deleted cluster is kept: %+v", clusters)
+	}
+}
+
+func TestProcessEventRecordsFailure(t *testing.T) {
+	p, _, clientset := newTestProcessor(t)
+	clientset.PrependReactor("create", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	})
+
+	p.processEvent(spec.ZookeeperClusterWatchEvent{Type: "ADDED", Object: testCluster()})
+	clusters := p.Clusters()
+	if len(clusters) != 1 || clusters[0].Result != metrics.ResultError || clusters[0].Error != "forbidden" {
+		t.Errorf("status after a failed ADDED is %+v", clusters)
+	}
+}
+
+func TestProcessEventSkipsClusterInRecovery(t *testing.T) {
+	p, _, clientset := newTestProcessor(t)
+	cluster := testCluster()
+	p.recoveriesRunning[clusterKey(cluster)] = true
+
+	p.processEvent(spec.ZookeeperClusterWatchEvent{Type: "UPDATED", Object: cluster})
+	if actions := clientset.Actions(); len(actions) != 0 {
+		t.Errorf("event of a cluster in recovery called the API: %v", actions)
+	}
+}
+
+func TestUserFinalizer(t *testing.T) {
+	p, controller, clientset := newTestProcessor(t)
+	user := spec.ZookeeperUser{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"},
+		Spec:       spec.ZookeeperUserSpec{ClusterName: "zk"},
+	}
+
+	p.processUserEvent(spec.ZookeeperUserWatchEvent{Type: "ADDED", Object: user})
+	if len(controller.users) == 0 || !hasUserFinalizer(controller.users[0]) {
+		t.Fatalf("ADDED didn't add the finalizer first: %+v", controller.users)
+	}
+
+	// the cluster is gone, so there are no grants left to revoke
+	deleting := controller.users[0]
+	now := metav1.Now()
+	deleting.ObjectMeta.DeletionTimestamp = &now
+	deleting.State = spec.ZookeeperUserState{Digest: "app:digest", Grants: []spec.ZnodeACL{{Path: "/app", Permissions: []string{"all"}}}}
+	controller.users = nil
+	p.processUserEvent(spec.ZookeeperUserWatchEvent{Type: "UPDATED", Object: deleting})
+	if len(controller.users) != 1 {
+		t.Fatalf("got %d updates of the deleted user, want 1", len(controller.users))
+	}
+	if released := controller.users[0]; hasUserFinalizer(released) || released.State.Digest != "" || len(released.State.Grants) != 0 {
+		t.Errorf("deleted user was released as %+v", released)
+	}
+	if _, err := clientset.CoreV1().Secrets("test").Get(user.GetSecretName(), metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("deleted user kept its Secret: %v", err)
+	}
+}
+
+func TestRunAndShutdown(t *testing.T) {
+	p, controller, clientset := newTestProcessor(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := p.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !p.Synced() || p.Healthy() != nil {
+		t.Fatalf("running processor is not synced and healthy: %v", p.Healthy())
+	}
+
+	controller.mutex.Lock()
+	events := controller.clusterEvents
+	controller.mutex.Unlock()
+	metrics.ClusterQueue.Add()
+	events <- spec.ZookeeperClusterWatchEvent{Type: "ADDED", Object: testCluster()}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(p.Clusters()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("event sent to the processor was not processed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := clientset.AppsV1().StatefulSets("test").Get("zk", metav1.GetOptions{}); err != nil {
+		t.Errorf("StatefulSet not created: %v", err)
+	}
+
+	finished := make(chan struct{})
+	p.goWork(func() {
+		time.Sleep(50 * time.Millisecond)
+		close(finished)
+	})
+	cancel()
+	if err := p.Shutdown(5 * time.Second); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	select {
+	case <-finished:
+	default:
+		t.Error("Shutdown returned before the work in flight finished")
+	}
+	if p.Healthy() == nil {
+		t.Error("stopped processor reports healthy")
+	}
+
+	started := false
+	p.goWork(func() { started = true })
+	if started || p.Run(context.Background()) == nil {
+		t.Error("processor accepted work after Shutdown")
+	}
+}
+
+func TestShutdownInterruptsWork(t *testing.T) {
+	p, _, _ := newTestProcessor(t)
+	interrupted := make(chan struct{})
+	p.goWork(func() {
+		<-p.ctx.Done()
+		close(interrupted)
+	})
+
+	if err := p.Shutdown(10 * time.Millisecond); err == nil {
+		t.Error("Shutdown didn't report work that outlived the timeout")
+	}
+	select {
+	case <-interrupted:
+	default:
+		t.Error("context of the work in flight was not cancelled")
+	}
+}
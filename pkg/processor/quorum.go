@@ -0,0 +1,106 @@
+package processor
+
+import (
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/metrics"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/zookeeper"
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+const quorumCheckInterval = 30 * time.Second
+
+// watchQuorum periodically checks every cluster for a serving majority and updates the ensemble
+// metrics until the processor stops.
+func (p *Processor) watchQuorum() {
+	ticker := time.NewTicker(quorumCheckInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.checkQuorums()
+			case <-p.done:
+				return
+			}
+		}
+	}()
+}
+
+func (p *Processor) checkQuorums() {
+	clusters, err := p.crdController.ListZookeeperClusters()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"method": "checkQuorums",
+			"error":  err,
+		}).Error("Cant list clusters")
+		return
+	}
+
+	checked := make(map[string]bool, len(clusters))
+	for _, cluster := range clusters {
+		key := clusterKey(cluster)
+		checked[key] = true
+		// restores and recoveries stop the ensemble on purpose
+		if cluster.Spec.BrokerCount == 0 || p.restoreRunning(cluster) || p.recoveryRunning(cluster) {
+			continue
+		}
+		p.checkQuorum(cluster)
+	}
+
+	p.quorumMutex.Lock()
+	for key := range p.quorum {
+		if !checked[key] {
+			delete(p.quorum, key)
+		}
+	}
+	p.quorumMutex.Unlock()
+	metrics.RetainEnsembles(func(namespace, name string) bool {
+		return checked[namespace+"/"+name]
+	})
+}
+
+// checkQuorum records an event when a cluster loses or regains its serving majority. A cluster
+// without quorum when it is first seen is still starting up and not reported.
+func (p *Processor) checkQuorum(cluster spec.ZookeeperCluster) {
+	members := zookeeper.MemberStats(cluster)
+	metrics.ObserveEnsemble(cluster.ObjectMeta.Namespace, cluster.ObjectMeta.Name, members)
+
+	serving := 0
+	for _, stats := range members {
+		// members without quorum refuse to serve srvr requests
+		if stats != nil && stats.Error == nil && stats.Mode != zk.ModeUnknown {
+			serving++
+		}
+	}
+	majority := int(cluster.Spec.BrokerCount)/2 + 1
+	hasQuorum := serving >= majority
+
+	key := clusterKey(cluster)
+	p.quorumMutex.Lock()
+	hadQuorum, known := p.quorum[key]
+	if known || hasQuorum {
+		p.quorum[key] = hasQuorum
+	}
+	p.quorumMutex.Unlock()
+
+	switch {
+	case known && hadQuorum && !hasQuorum:
+		log.WithFields(log.Fields{
+			"method":      "checkQuorum",
+			"clusterName": cluster.ObjectMeta.Name,
+			"namespace":   cluster.ObjectMeta.Namespace,
+			"serving":     serving,
+		}).Warn("Cluster lost quorum")
+		p.recorder.Eventf(&cluster, v1.EventTypeWarning, kube.EventReasonQuorumLost,
+			"Only %d of %d members are serving, a quorum needs %d", serving, cluster.Spec.BrokerCount, majority)
+	case known && !hadQuorum && hasQuorum:
+		p.recorder.Eventf(&cluster, v1.EventTypeNormal, kube.EventReasonQuorumRestored,
+			"%d of %d members are serving again", serving, cluster.Spec.BrokerCount)
+	}
+}
@@ -0,0 +1,87 @@
+package processor
+
+import (
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/recovery"
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+// recoveryNeeded reports whether the recover annotation asks for a recovery that hasn't been carried out yet.
+// Setting the annotation to a new value triggers another recovery, an interrupted one is resumed.
+func recoveryNeeded(clusterSpec spec.ZookeeperCluster) bool {
+	trigger := clusterSpec.ObjectMeta.Annotations[spec.RecoverAnnotation]
+	if trigger == "" {
+		return false
+	}
+	state := clusterSpec.State.Recovery
+	return state == nil || state.Trigger != trigger || state.Phase == spec.RecoveryPhaseRunning
+}
+
+func (p *Processor) recoveryRunning(clusterSpec spec.ZookeeperCluster) bool {
+	p.recoveriesMutex.Lock()
+	defer p.recoveriesMutex.Unlock()
+	return p.recoveriesRunning[clusterKey(clusterSpec)]
+}
+
+// recoverZookeeperCluster rebuilds an ensemble that lost quorum from its most up to date member.
+func (p *Processor) recoverZookeeperCluster(clusterSpec spec.ZookeeperCluster) {
+	methodLogger := log.WithFields(log.Fields{
+		"method":      "recoverZookeeperCluster",
+		"clusterName": clusterSpec.ObjectMeta.Name,
+		"namespace":   clusterSpec.ObjectMeta.Namespace,
+	})
+	key := clusterKey(clusterSpec)
+
+	p.recoveriesMutex.Lock()
+	if p.recoveriesRunning[key] {
+		p.recoveriesMutex.Unlock()
+		return
+	}
+	p.recoveriesRunning[key] = true
+	p.recoveriesMutex.Unlock()
+	defer func() {
+		p.recoveriesMutex.Lock()
+		delete(p.recoveriesRunning, key)
+		p.recoveriesMutex.Unlock()
+	}()
+
+	trigger := clusterSpec.ObjectMeta.Annotations[spec.RecoverAnnotation]
+	methodLogger.WithField("trigger", trigger).Warn("Recovering cluster from quorum loss")
+	err := recovery.Run(p.ctx, clusterSpec, p.kube, p.recorder, trigger, func(state spec.RecoveryState) {
+		p.updateRecoveryState(clusterSpec, state)
+	})
+	if err != nil && p.ctx.Err() != nil {
+		methodLogger.WithField("error", err).Warn("Recovery interrupted by shutdown")
+		return
+	}
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Recovery failed")
+		return
+	}
+	methodLogger.Info("Recovery completed")
+}
+
+// updateRecoveryState writes the recovery state to the latest version of the cluster.
+func (p *Processor) updateRecoveryState(clusterSpec spec.ZookeeperCluster, state spec.RecoveryState) {
+	methodLogger := log.WithFields(log.Fields{
+		"method":      "updateRecoveryState",
+		"clusterName": clusterSpec.ObjectMeta.Name,
+		"namespace":   clusterSpec.ObjectMeta.Namespace,
+	})
+
+	// the cached cluster may lag behind the API server, a conflict is retried on a newer copy
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cluster, err := p.crdController.GetZookeeperCluster(clusterSpec.ObjectMeta.Namespace, clusterSpec.ObjectMeta.Name)
+		if err != nil {
+			return err
+		}
+		cluster.State.Recovery = &state
+		_, err = p.crdController.UpdateZookeeperCluster(cluster)
+		return err
+	})
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant update cluster state")
+	}
+}
@@ -0,0 +1,194 @@
+package processor
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/backup"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/zookeeper"
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+const restoreVerifyTimeout = 15 * time.Minute
+
+// restoreNeeded reports whether the cluster asks for a restore that hasn't been carried out yet.
+// A restore interrupted while seeding is resumed.
+func restoreNeeded(clusterSpec spec.ZookeeperCluster) bool {
+	if clusterSpec.Spec.RestoreFrom == nil {
+		return false
+	}
+	state := clusterSpec.State.Restore
+	switch {
+	case state == nil:
+		return true
+	case state.Phase == spec.RestorePhaseSeeding:
+		return true
+	case state.Phase == spec.RestorePhaseRefused:
+		return clusterSpec.Spec.RestoreFrom.Force
+	}
+	return false
+}
+
+func (p *Processor) restoreRunning(clusterSpec spec.ZookeeperCluster) bool {
+	p.restoresMutex.Lock()
+	defer p.restoresMutex.Unlock()
+	return p.restoresRunning[clusterKey(clusterSpec)]
+}
+
+// restoreZookeeperCluster seeds all members from the backup, starts the ensemble and verifies its zxid.
+func (p *Processor) restoreZookeeperCluster(clusterSpec spec.ZookeeperCluster) {
+	methodLogger := log.WithFields(log.Fields{
+		"method":      "restoreZookeeperCluster",
+		"clusterName": clusterSpec.ObjectMeta.Name,
+		"namespace":   clusterSpec.ObjectMeta.Namespace,
+	})
+	key := clusterKey(clusterSpec)
+
+	p.restoresMutex.Lock()
+	if p.restoresRunning[key] {
+		p.restoresMutex.Unlock()
+		return
+	}
+	p.restoresRunning[key] = true
+	p.restoresMutex.Unlock()
+	defer func() {
+		p.restoresMutex.Lock()
+		delete(p.restoresRunning, key)
+		p.restoresMutex.Unlock()
+	}()
+
+	source := *clusterSpec.Spec.RestoreFrom
+	resuming := clusterSpec.State.Restore != nil && clusterSpec.State.Restore.Phase == spec.RestorePhaseSeeding
+
+	if !resuming && !source.Force {
+		hasData, err := kube.HasData(clusterSpec, p.kube)
+		if err != nil {
+			methodLogger.WithField("error", err).Error("Cant check for existing data")
+			return
+		}
+		if hasData {
+			methodLogger.Warn("Refusing to restore into a cluster that already has data")
+			p.updateRestoreState(clusterSpec, spec.RestoreState{
+				Phase:   spec.RestorePhaseRefused,
+				Backup:  source.Backup,
+				Message: "cluster already has data, set restoreFrom.force to replace it",
+			})
+			p.createZookeeperCluster(clusterSpec)
+			return
+		}
+	}
+
+	state := spec.RestoreState{
+		Phase:  spec.RestorePhaseSeeding,
+		Backup: source.Backup,
+	}
+	fail := func(err error) {
+		if p.ctx.Err() != nil {
+			// the phase is kept, a restore interrupted while seeding is resumed
+			methodLogger.WithField("error", err).Warn("Restore interrupted by shutdown")
+			return
+		}
+		methodLogger.WithField("error", err).Error("Restore failed")
+		state.Phase = spec.RestorePhaseFailed
+		state.Message = err.Error()
+		p.updateRestoreState(clusterSpec, state)
+	}
+
+	backupSpec, err := p.crdController.GetZookeeperBackup(clusterSpec.ObjectMeta.Namespace, source.Backup)
+	if err != nil {
+		fail(err)
+		return
+	}
+	store, err := p.backupStore(*backupSpec)
+	if err != nil {
+		fail(err)
+		return
+	}
+	state.Archive = source.Archive
+	if state.Archive == "" {
+		state.Archive, err = backup.Latest(store)
+		if err != nil {
+			fail(err)
+			return
+		}
+	}
+	zxid, zxidKnown := archiveZxid(*backupSpec, state.Archive)
+	if zxidKnown {
+		state.Zxid = zookeeper.FormatZxid(zxid)
+	}
+	p.updateRestoreState(clusterSpec, state)
+
+	methodLogger.WithField("archive", state.Archive).Info("Restoring cluster from backup")
+	err = backup.Restore(p.ctx, clusterSpec, p.kube, store, state.Archive)
+	if err != nil {
+		fail(err)
+		return
+	}
+	if zxidKnown {
+		err = backup.VerifySeed(p.ctx, clusterSpec, zxid, p.kube)
+		if err != nil {
+			fail(err)
+			return
+		}
+	}
+
+	state.Phase = spec.RestorePhaseSeeded
+	p.updateRestoreState(clusterSpec, state)
+	p.createZookeeperCluster(clusterSpec)
+
+	if !zxidKnown {
+		methodLogger.Warn("Zxid of the archive is unknown, skipping verification")
+		return
+	}
+	err = backup.VerifyRestore(p.ctx, clusterSpec, zxid, restoreVerifyTimeout)
+	if err != nil {
+		fail(err)
+		return
+	}
+	state.Phase = spec.RestorePhaseVerified
+	p.updateRestoreState(clusterSpec, state)
+	methodLogger.Info("Restore verified")
+}
+
+// archiveZxid looks up the zxid recorded for an archive, falling back to the one encoded in its name.
+func archiveZxid(backupSpec spec.ZookeeperBackup, archive string) (int64, bool) {
+	for _, record := range backupSpec.State.Backups {
+		if record.Name != archive {
+			continue
+		}
+		if zxid, err := zookeeper.ParseZxid(record.Zxid); err == nil {
+			return zxid, true
+		}
+	}
+	return backup.ArchiveZxid(archive)
+}
+
+// updateRestoreState writes the restore state to the latest version of the cluster.
+func (p *Processor) updateRestoreState(clusterSpec spec.ZookeeperCluster, state spec.RestoreState) {
+	methodLogger := log.WithFields(log.Fields{
+		"method":      "updateRestoreState",
+		"clusterName": clusterSpec.ObjectMeta.Name,
+		"namespace":   clusterSpec.ObjectMeta.Namespace,
+	})
+
+	// the cached cluster may lag behind the API server, a conflict is retried on a newer copy
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		cluster, err := p.crdController.GetZookeeperCluster(clusterSpec.ObjectMeta.Namespace, clusterSpec.ObjectMeta.Name)
+		if err != nil {
+			return err
+		}
+		cluster.State.Restore = &state
+		_, err = p.crdController.UpdateZookeeperCluster(cluster)
+		return err
+	})
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant update cluster state")
+	}
+}
+
+func clusterKey(clusterSpec spec.ZookeeperCluster) string {
+	return clusterSpec.ObjectMeta.Namespace + "/" + clusterSpec.ObjectMeta.Name
+}
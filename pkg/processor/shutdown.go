@@ -0,0 +1,64 @@
+package processor
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// interruptGracePeriod is how long Shutdown waits for interrupted work to record its state.
+const interruptGracePeriod = 5 * time.Second
+
+// goWork runs f in the background unless the processor is shutting down. Shutdown waits for it.
+func (p *Processor) goWork(f func()) {
+	p.workMutex.Lock()
+	defer p.workMutex.Unlock()
+	if p.stopping {
+		return
+	}
+	p.work.Add(1)
+	go func() {
+		defer p.work.Done()
+		f()
+	}()
+}
+
+// Shutdown stops starting restores, recoveries and backups and waits until the event loop and
+// the ones in flight returned. The event loop has to be stopped by cancelling the context of Run.
+// Work still running after timeout is interrupted at its next wait, restores and recoveries
+// keep their phase and are resumed by the next leader.
+func (p *Processor) Shutdown(timeout time.Duration) error {
+	p.workMutex.Lock()
+	p.stopping = true
+	p.workMutex.Unlock()
+	defer p.cancelWork()
+
+	p.statusMutex.Lock()
+	started := p.started
+	p.statusMutex.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		if started {
+			<-p.done
+		}
+		p.work.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		log.Info("Processor stopped")
+		return nil
+	case <-time.After(timeout):
+	}
+
+	log.WithField("timeout", timeout).Warn("In-flight work didn't finish in time, interrupting it")
+	p.cancelWork()
+	select {
+	case <-drained:
+	case <-time.After(interruptGracePeriod):
+	}
+	return fmt.Errorf("in-flight work didn't finish within %v", timeout)
+}
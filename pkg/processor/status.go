@@ -0,0 +1,97 @@
+package processor
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/metrics"
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+// ClusterStatus is what the processor knows about a cluster, served on /clusters.
+type ClusterStatus struct {
+	Namespace     string    `json:"namespace"`
+	Name          string    `json:"name"`
+	BrokerCount   int32     `json:"brokerCount"`
+	LastEvent     string    `json:"lastEvent"`
+	LastReconcile time.Time `json:"lastReconcile"`
+	Result        string    `json:"result"`
+	Error         string    `json:"error,omitempty"`
+	// Quorum is unknown until the cluster was seen serving once
+	Quorum *bool `json:"quorum,omitempty"`
+}
+
+// recordReconcile remembers the outcome of the latest event processed for a cluster.
+func (p *Processor) recordReconcile(event spec.ZookeeperClusterWatchEvent, err error) {
+	key := clusterKey(event.Object)
+	p.statusMutex.Lock()
+	defer p.statusMutex.Unlock()
+	if event.Type == "DELETED" && err == nil {
+		delete(p.statuses, key)
+		return
+	}
+	status := ClusterStatus{
+		Namespace:     event.Object.ObjectMeta.Namespace,
+		Name:          event.Object.ObjectMeta.Name,
+		BrokerCount:   event.Object.Spec.BrokerCount,
+		LastEvent:     event.Type,
+		LastReconcile: time.Now(),
+		Result:        metrics.ResultSuccess,
+	}
+	if err != nil {
+		status.Result = metrics.ResultError
+		status.Error = err.Error()
+	}
+	p.statuses[key] = status
+}
+
+// Clusters lists every cluster the processor has seen, sorted by namespace and name.
+func (p *Processor) Clusters() []ClusterStatus {
+	p.statusMutex.Lock()
+	clusters := make([]ClusterStatus, 0, len(p.statuses))
+	for _, status := range p.statuses {
+		clusters = append(clusters, status)
+	}
+	p.statusMutex.Unlock()
+
+	p.quorumMutex.Lock()
+	for i := range clusters {
+		if quorum, known := p.quorum[clusters[i].Namespace+"/"+clusters[i].Name]; known {
+			clusters[i].Quorum = &quorum
+		}
+	}
+	p.quorumMutex.Unlock()
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Namespace != clusters[j].Namespace {
+			return clusters[i].Namespace < clusters[j].Namespace
+		}
+		return clusters[i].Name < clusters[j].Name
+	})
+	return clusters
+}
+
+// Healthy returns an error if the event loop or one of the informers of a running processor stopped.
+func (p *Processor) Healthy() error {
+	p.statusMutex.Lock()
+	started := p.started
+	p.statusMutex.Unlock()
+	if !started {
+		return nil
+	}
+	select {
+	case <-p.done:
+		return errors.New("event loop stopped")
+	default:
+	}
+	return p.crdController.InformersRunning()
+}
+
+// Synced reports whether the processor runs and its informers have listed all resources.
+func (p *Processor) Synced() bool {
+	p.statusMutex.Lock()
+	started := p.started
+	p.statusMutex.Unlock()
+	return started && p.crdController.HasSynced()
+}
@@ -0,0 +1,250 @@
+package processor
+
+import (
+	"reflect"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/zookeeper"
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+func (p *Processor) processUserEvent(currentEvent spec.ZookeeperUserWatchEvent) {
+	methodLogger := log.WithFields(log.Fields{
+		"method":                 "processUserEvent",
+		"userName":               currentEvent.Object.Name,
+		"ZookeeperUserEventType": currentEvent.Type,
+	})
+	methodLogger.Info("Caught new user event: ", currentEvent.Type)
+	switch {
+	case currentEvent.Type == "ADDED" || currentEvent.Type == "UPDATED":
+		p.processZookeeperUser(currentEvent.Object)
+
+	case currentEvent.Type == "DELETED":
+		p.deleteZookeeperUser(currentEvent.Object)
+	}
+}
+
+func (p *Processor) processZookeeperUser(user spec.ZookeeperUser) {
+	methodLogger := log.WithFields(log.Fields{
+		"method":    "processZookeeperUser",
+		"userName":  user.ObjectMeta.Name,
+		"namespace": user.ObjectMeta.Namespace,
+	})
+
+	if user.ObjectMeta.DeletionTimestamp != nil {
+		p.finalizeZookeeperUser(user)
+		return
+	}
+	if !hasUserFinalizer(user) {
+		// the grants are revoked before the user is removed, a missed DELETED event would leave them behind
+		updated := user.DeepCopy()
+		updated.ObjectMeta.Finalizers = append(updated.ObjectMeta.Finalizers, spec.UserFinalizer)
+		added, err := p.crdController.UpdateZookeeperUser(updated)
+		if err != nil {
+			methodLogger.WithField("error", err).Error("Cant add user finalizer")
+			return
+		}
+		user = *added
+	}
+
+	state := spec.ZookeeperUserState{
+		SecretName: user.GetSecretName(),
+		Grants:     user.State.Grants,
+		Digest:     user.State.Digest,
+	}
+
+	credentials, err := kube.CreateUser(user, p.kube)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant create user credentials")
+		state.Message = err.Error()
+		p.updateUserState(user, state)
+		return
+	}
+
+	client, err := p.userClusterClient(user)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant connect to zookeeper cluster")
+		state.Message = err.Error()
+		p.updateUserState(user, state)
+		return
+	}
+	defer client.Close()
+
+	// the Secret was recreated with a new password, the grants belong to the old digest
+	if user.State.Digest != "" && user.State.Digest != credentials.Digest {
+		err = client.RevokeDigestACL(user.State.Digest, user.State.Grants)
+		if err != nil {
+			methodLogger.WithField("error", err).Error("Cant revoke ACLs of previous credentials")
+			state.Message = err.Error()
+			p.updateUserState(user, state)
+			return
+		}
+		state.Grants = nil
+	}
+	state.Digest = credentials.Digest
+
+	// grants that were dropped from the spec since the last run
+	revoked := removedACLs(user.State.Grants, user.Spec.ACLs)
+	err = client.RevokeDigestACL(credentials.Digest, revoked)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant revoke user ACLs")
+		state.Message = err.Error()
+		p.updateUserState(user, state)
+		return
+	}
+
+	err = client.GrantDigestACL(credentials.Digest, user.Spec.ACLs)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant grant user ACLs")
+		state.Message = err.Error()
+		p.updateUserState(user, state)
+		return
+	}
+
+	state.Grants = user.Spec.ACLs
+	state.Message = ""
+	p.updateUserState(user, state)
+}
+
+// finalizeZookeeperUser revokes the grants of a user being deleted and then releases it.
+// It is retried on every event of the user until the grants are revoked.
+func (p *Processor) finalizeZookeeperUser(user spec.ZookeeperUser) {
+	methodLogger := log.WithFields(log.Fields{
+		"method":    "finalizeZookeeperUser",
+		"userName":  user.ObjectMeta.Name,
+		"namespace": user.ObjectMeta.Namespace,
+	})
+	if !hasUserFinalizer(user) {
+		return
+	}
+
+	err := p.revokeUserACLs(user)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant revoke user ACLs")
+		state := user.State
+		state.Message = err.Error()
+		p.updateUserState(user, state)
+		return
+	}
+	err = kube.DeleteUser(user, p.kube)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant delete user credentials")
+		return
+	}
+
+	updated := user.DeepCopy()
+	updated.ObjectMeta.Finalizers = nil
+	for _, finalizer := range user.ObjectMeta.Finalizers {
+		if finalizer != spec.UserFinalizer {
+			updated.ObjectMeta.Finalizers = append(updated.ObjectMeta.Finalizers, finalizer)
+		}
+	}
+	// nothing is left for the DELETED event to revoke
+	updated.State.Grants = nil
+	updated.State.Digest = ""
+	_, err = p.crdController.UpdateZookeeperUser(updated)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant remove user finalizer")
+		return
+	}
+	methodLogger.Info("Revoked ACLs of deleted user")
+}
+
+// deleteZookeeperUser cleans up after users that were deleted without the finalizer.
+func (p *Processor) deleteZookeeperUser(user spec.ZookeeperUser) {
+	methodLogger := log.WithFields(log.Fields{
+		"method":    "deleteZookeeperUser",
+		"userName":  user.ObjectMeta.Name,
+		"namespace": user.ObjectMeta.Namespace,
+	})
+
+	err := p.revokeUserACLs(user)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant revoke user ACLs")
+	}
+
+	err = kube.DeleteUser(user, p.kube)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant delete user credentials")
+	}
+}
+
+// revokeUserACLs removes every grant of the user. Without its cluster there is nothing left to revoke.
+func (p *Processor) revokeUserACLs(user spec.ZookeeperUser) error {
+	digest := user.State.Digest
+	if digest == "" {
+		// users granted before the digest was kept in their state
+		secret, err := p.kube.GetSecret(user.ObjectMeta.Namespace, user.GetSecretName())
+		if err != nil {
+			return err
+		}
+		if secret != nil {
+			digest = string(secret.Data["digest"])
+		}
+	}
+	if digest == "" {
+		return nil
+	}
+
+	client, err := p.userClusterClient(user)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	grants := append(removedACLs(user.State.Grants, user.Spec.ACLs), user.Spec.ACLs...)
+	return client.RevokeDigestACL(digest, grants)
+}
+
+func hasUserFinalizer(user spec.ZookeeperUser) bool {
+	for _, finalizer := range user.ObjectMeta.Finalizers {
+		if finalizer == spec.UserFinalizer {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Processor) userClusterClient(user spec.ZookeeperUser) (*zookeeper.Client, error) {
+	cluster, err := p.crdController.GetZookeeperCluster(user.ObjectMeta.Namespace, user.Spec.ClusterName)
+	if err != nil {
+		return nil, err
+	}
+	return zookeeper.New(zookeeper.Servers(*cluster), p.zookeeperAuth)
+}
+
+func (p *Processor) updateUserState(user spec.ZookeeperUser, state spec.ZookeeperUserState) {
+	if reflect.DeepEqual(user.State, state) {
+		return
+	}
+	updated := user.DeepCopy()
+	updated.State = state
+	_, err := p.crdController.UpdateZookeeperUser(updated)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"method":   "updateUserState",
+			"userName": user.ObjectMeta.Name,
+			"error":    err,
+		}).Error("Cant update user state")
+	}
+}
+
+// removedACLs returns the entries of previous whose path is no longer in current.
+func removedACLs(previous, current []spec.ZnodeACL) []spec.ZnodeACL {
+	paths := make(map[string]bool, len(current))
+	for _, acl := range current {
+		paths[acl.Path] = true
+	}
+	var removed []spec.ZnodeACL
+	for _, acl := range previous {
+		if !paths[acl.Path] {
+			removed = append(removed, acl)
+		}
+	}
+	return removed
+}
@@ -0,0 +1,255 @@
+package recovery
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/backup"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/zookeeper"
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+const (
+	startTimeout = 15 * time.Minute
+	modeInterval = 5 * time.Second
+)
+
+// copyScript archives the snapshots and transaction logs of a member, the log dir may not exist yet.
+var copyScript = fmt.Sprintf("cd %s && tar czf - $(ls -d data/version-2 log/version-2 2>/dev/null)", backup.VolumeDir)
+
+var (
+	logger = log.WithFields(log.Fields{
+		"package": "recovery",
+	})
+)
+
+type recovery struct {
+	ctx      context.Context
+	cluster  spec.ZookeeperCluster
+	client   kube.Kubernetes
+	recorder record.EventRecorder
+	state    spec.RecoveryState
+	save     func(spec.RecoveryState)
+}
+
+// Run rebuilds an ensemble that lost quorum from its surviving member with the
+// highest zxid:
+//
+//  1. all members are stopped and the data volume of every member is inspected
+//  2. the survivor's data is copied to member 0 unless it is member 0
+//  3. the data volumes of all other members are deleted
+//  4. member 0 is started as a single node ensemble
+//  5. the ensemble is restarted with a quorum that elects member 0, the other members
+//     join one at a time and resync from it
+//
+// Every step is published as an event on the cluster and passed to save. If ctx is done
+// before the recovery completes it stays in the running phase and can be resumed.
+func Run(ctx context.Context, cluster spec.ZookeeperCluster, client kube.Kubernetes, recorder record.EventRecorder, trigger string, save func(spec.RecoveryState)) error {
+	r := &recovery{
+		ctx:      ctx,
+		cluster:  cluster,
+		client:   client,
+		recorder: recorder,
+		state: spec.RecoveryState{
+			Phase:   spec.RecoveryPhaseRunning,
+			Trigger: trigger,
+		},
+		save: save,
+	}
+
+	err := r.run()
+	if err != nil && ctx.Err() != nil {
+		r.step(v1.EventTypeWarning, "Interrupted", "Operator is shutting down, the recovery resumes once it runs again")
+		return err
+	}
+	if err != nil {
+		r.state.Phase = spec.RecoveryPhaseFailed
+		r.step(v1.EventTypeWarning, "Failed", err.Error())
+		return err
+	}
+	r.state.Phase = spec.RecoveryPhaseCompleted
+	r.step(v1.EventTypeNormal, "Completed", fmt.Sprintf("Ensemble rebuilt from %s", r.state.Survivor))
+	return nil
+}
+
+func (r *recovery) run() error {
+	cluster := r.cluster
+	members := int(cluster.Spec.BrokerCount)
+
+	r.step(v1.EventTypeNormal, "Stopping", "Stopping all members")
+	err := kube.StopCluster(r.ctx, cluster, r.client)
+	if err != nil {
+		return err
+	}
+
+	r.step(v1.EventTypeNormal, "Inspecting", "Looking for the surviving member with the highest zxid")
+	survivor, zxid, err := r.findSurvivor()
+	if err != nil {
+		return err
+	}
+	r.state.Survivor = memberName(cluster, survivor)
+	r.state.Zxid = zookeeper.FormatZxid(zxid)
+	r.step(v1.EventTypeNormal, "SurvivorSelected", fmt.Sprintf("%s has the highest zxid %s", r.state.Survivor, r.state.Zxid))
+
+	if survivor != 0 {
+		r.step(v1.EventTypeNormal, "Seeding", fmt.Sprintf("Copying the data of %s to %s", r.state.Survivor, memberName(cluster, 0)))
+		err = r.copyData(survivor, 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	for ordinal := 1; ordinal < members; ordinal++ {
+		r.step(v1.EventTypeNormal, "Wiping", fmt.Sprintf("Deleting the data volume of %s", memberName(cluster, ordinal)))
+		err = kube.DeleteDataVolume(r.ctx, cluster, ordinal, r.client)
+		if err != nil {
+			return err
+		}
+	}
+
+	single := *cluster.DeepCopy()
+	single.Spec.BrokerCount = 1
+	r.step(v1.EventTypeNormal, "SingleNode", fmt.Sprintf("Starting %s as a single node ensemble", memberName(cluster, 0)))
+	err = kube.CreateCluster(single, r.client)
+	if err != nil {
+		return err
+	}
+	err = backup.VerifyRestore(r.ctx, single, zxid, startTimeout)
+	if err != nil {
+		return err
+	}
+
+	if members > 1 {
+		r.step(v1.EventTypeNormal, "Rejoining", fmt.Sprintf("Restarting with all %d members, they resync from %s", members, memberName(cluster, 0)))
+		err = kube.StopCluster(r.ctx, single, r.client)
+		if err != nil {
+			return err
+		}
+		err = r.rejoin()
+		if err != nil {
+			return err
+		}
+		return backup.VerifyRestore(r.ctx, cluster, zxid, startTimeout)
+	}
+	return nil
+}
+
+// rejoin starts the ensemble so that member 0 leads it. Empty members that make up a quorum on
+// their own, which members started in parallel could, would elect one of them and make member 0
+// drop its data. So the first start is the smallest quorum, which can't elect a leader without
+// member 0 and elects it for its highest zxid, and every further member joins that leader alone.
+func (r *recovery) rejoin() error {
+	members := r.cluster.Spec.BrokerCount
+	quorum := members/2 + 1
+	err := kube.StartMembers(r.cluster, quorum, r.client)
+	if err != nil {
+		return err
+	}
+	err = r.waitForMode(0, zk.ModeLeader)
+	if err != nil {
+		return err
+	}
+	for replicas := quorum + 1; replicas <= members; replicas++ {
+		err = kube.StartMembers(r.cluster, replicas, r.client)
+		if err != nil {
+			return err
+		}
+		err = r.waitForMode(int(replicas-1), zk.ModeFollower)
+		if err != nil {
+			return err
+		}
+	}
+	return kube.CreateCluster(r.cluster, r.client)
+}
+
+// waitForMode waits until a member serves in the given mode.
+func (r *recovery) waitForMode(ordinal int, mode zk.Mode) error {
+	err := kube.Poll(r.ctx, modeInterval, startTimeout, func() (bool, error) {
+		stats := zookeeper.MemberStats(r.cluster)
+		return ordinal < len(stats) && stats[ordinal] != nil && stats[ordinal].Error == nil && stats[ordinal].Mode == mode, nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s doesn't serve as %s: %v", memberName(r.cluster, ordinal), mode, err)
+	}
+	return nil
+}
+
+// findSurvivor returns the member whose data volume holds the highest zxid, in its newest
+// snapshot or in its transaction logs.
+func (r *recovery) findSurvivor() (int, int64, error) {
+	survivor, survivorZxid := -1, int64(-1)
+	for ordinal := 0; ordinal < int(r.cluster.Spec.BrokerCount); ordinal++ {
+		exists, err := kube.DataVolumeExists(r.cluster, ordinal, r.client)
+		if err != nil {
+			return 0, 0, err
+		}
+		if !exists {
+			logger.WithField("member", memberName(r.cluster, ordinal)).Info("Data volume is lost")
+			continue
+		}
+
+		zxid, err := backup.DataZxid(r.ctx, r.cluster, ordinal, r.client)
+		if err != nil {
+			return 0, 0, err
+		}
+		if zxid < 0 {
+			logger.WithField("member", memberName(r.cluster, ordinal)).Info("Data volume is empty")
+			continue
+		}
+		if zxid > survivorZxid {
+			survivor, survivorZxid = ordinal, zxid
+		}
+	}
+	if survivor < 0 {
+		return 0, 0, fmt.Errorf("no member with data left, restore from a backup instead")
+	}
+	return survivor, survivorZxid, nil
+}
+
+// copyData streams the snapshots and transaction logs of one member onto the data volume of another.
+func (r *recovery) copyData(from, to int) error {
+	reader, writer := io.Pipe()
+	seeded := make(chan error, 1)
+	go func() {
+		err := kube.SeedDataVolume(r.ctx, r.cluster, to, reader, r.client)
+		reader.CloseWithError(err)
+		seeded <- err
+	}()
+
+	command := []string{"sh", "-c", copyScript}
+	err := kube.ExecOnDataVolume(r.ctx, r.cluster, from, command, nil, writer, r.client)
+	writer.CloseWithError(err)
+	if seedErr := <-seeded; err == nil {
+		err = seedErr
+	}
+	return err
+}
+
+func (r *recovery) step(eventType, step, message string) {
+	logger.WithFields(log.Fields{
+		"cluster":   r.cluster.ObjectMeta.Name,
+		"namespace": r.cluster.ObjectMeta.Namespace,
+		"step":      step,
+	}).Info(message)
+
+	r.state.Steps = append(r.state.Steps, spec.RecoveryStep{
+		Time:    metav1.Now(),
+		Step:    step,
+		Message: message,
+	})
+	r.recorder.Event(&r.cluster, eventType, "Recovery"+step, message)
+	r.save(r.state)
+}
+
+func memberName(cluster spec.ZookeeperCluster, ordinal int) string {
+	return fmt.Sprintf("%s-%d", cluster.ObjectMeta.Name, ordinal)
+}
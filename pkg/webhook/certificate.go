@@ -0,0 +1,142 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
+)
+
+const (
+	certificateValidity = 10 * 365 * 24 * time.Hour
+	// certificates expiring within renewBefore are replaced on startup
+	renewBefore = 30 * 24 * time.Hour
+
+	caCertKey = "ca.crt"
+)
+
+// ensureCertificate returns the serving certificate of the webhook and the CA that signed it.
+// Both are kept in a Secret so that all operator replicas serve the same certificate; a
+// missing, expiring or mismatching certificate is replaced by a freshly generated one.
+func ensureCertificate(client kube.Kubernetes, namespace, secretName, dnsName string) (tls.Certificate, []byte, error) {
+	secrets := client.Client.CoreV1().Secrets(namespace)
+
+	secret, err := secrets.Get(secretName, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return tls.Certificate{}, nil, err
+	}
+	exists := err == nil
+	if exists && certificateValid(secret, dnsName) {
+		cert, err := tls.X509KeyPair(secret.Data[v1.TLSCertKey], secret.Data[v1.TLSPrivateKeyKey])
+		return cert, secret.Data[caCertKey], err
+	}
+
+	data, err := generateCertificate(dnsName)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	if !exists {
+		secret = &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secretName,
+				Namespace: namespace,
+			},
+			Type: v1.SecretTypeTLS,
+			Data: data,
+		}
+		_, err = secrets.Create(secret)
+		if errors.IsAlreadyExists(err) {
+			// another replica was faster, use its certificate
+			return ensureCertificate(client, namespace, secretName, dnsName)
+		}
+	} else {
+		secret.Data = data
+		_, err = secrets.Update(secret)
+	}
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+
+	cert, err := tls.X509KeyPair(data[v1.TLSCertKey], data[v1.TLSPrivateKeyKey])
+	return cert, data[caCertKey], err
+}
+
+func certificateValid(secret *v1.Secret, dnsName string) bool {
+	block, _ := pem.Decode(secret.Data[v1.TLSCertKey])
+	if block == nil || len(secret.Data[caCertKey]) == 0 {
+		return false
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false
+	}
+	return cert.VerifyHostname(dnsName) == nil && time.Now().Add(renewBefore).Before(cert.NotAfter)
+}
+
+// generateCertificate creates a self-signed CA and a serving certificate for dnsName signed by it.
+func generateCertificate(dnsName string) (map[string][]byte, error) {
+	now := time.Now()
+
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "zookeeper-operator-webhook-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certificateValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{dnsName},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certificateValidity),
+		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]byte{
+		caCertKey:           encodePEM("CERTIFICATE", caDER),
+		v1.TLSCertKey:       encodePEM("CERTIFICATE", certDER),
+		v1.TLSPrivateKeyKey: encodePEM("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key)),
+	}, nil
+}
+
+func encodePEM(blockType string, der []byte) []byte {
+	var buf bytes.Buffer
+	pem.Encode(&buf, &pem.Block{Type: blockType, Bytes: der})
+	return buf.Bytes()
+}
@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// defaultCluster returns the JSON patch that stores the defaulted resources on the cluster,
+// or nil when all of them are set.
+func defaultCluster(cluster *spec.ZookeeperCluster) ([]byte, error) {
+	defaulted := cluster.Spec
+	spec.SetDefaults(&defaulted)
+	if defaulted.Resources == cluster.Spec.Resources {
+		return nil, nil
+	}
+	return json.Marshal([]patchOperation{{
+		Op:    "add",
+		Path:  "/spec/resources",
+		Value: defaulted.Resources,
+	}})
+}
+
+// validateCluster checks the rules that the OpenAPI schema of the CRD can't express.
+// oldCluster is nil on creation. On updates the rules on a field are only checked when it
+// changed, so the operator can still write the state of clusters created before a rule.
+func validateCluster(cluster, oldCluster *spec.ZookeeperCluster) []string {
+	var violations []string
+	clusterSpec := cluster.Spec
+	spec.SetDefaults(&clusterSpec)
+	var oldSpec *spec.ZookeeperClusterSpec
+	if oldCluster != nil {
+		oldSpec = oldCluster.Spec.DeepCopy()
+		spec.SetDefaults(oldSpec)
+	}
+
+	if clusterSpec.BrokerCount%2 == 0 && (oldSpec == nil || oldSpec.BrokerCount != clusterSpec.BrokerCount) {
+		violations = append(violations, fmt.Sprintf("brokerCount must be odd to tolerate failures with a quorum, got %d", clusterSpec.BrokerCount))
+	}
+
+	memory, err := resource.ParseQuantity(clusterSpec.Resources.Memory)
+	if err != nil {
+		violations = append(violations, fmt.Sprintf("resources.memory: %v", err))
+	}
+	heap, heapErr := resource.ParseQuantity(clusterSpec.Resources.JvmHeap)
+	if heapErr != nil {
+		violations = append(violations, fmt.Sprintf("resources.jvmHeap: %v", heapErr))
+	}
+	heapChanged := oldSpec == nil || oldSpec.Resources.Memory != clusterSpec.Resources.Memory ||
+		oldSpec.Resources.JvmHeap != clusterSpec.Resources.JvmHeap
+	if err == nil && heapErr == nil && heapChanged && heap.Cmp(memory) > 0 {
+		violations = append(violations, fmt.Sprintf("resources.jvmHeap %s exceeds resources.memory %s", heap.String(), memory.String()))
+	}
+
+	diskSpace, err := resource.ParseQuantity(clusterSpec.Resources.DiskSpace)
+	if err != nil {
+		violations = append(violations, fmt.Sprintf("resources.diskSpace: %v", err))
+	}
+
+	if probes := clusterSpec.Probes; probes != nil && probes.Mode == spec.ProbeModeZkutil && probes.Image == "" {
+		violations = append(violations, "probes.image is required by the zkutil probe mode")
+	}
+
+	if oldSpec == nil {
+		return violations
+	}
+
+	// the next odd count below keeps a quorum of the remaining members, an even count is never a step
+	nextOdd := oldSpec.BrokerCount - 1 - oldSpec.BrokerCount%2
+	if nextOdd < 0 {
+		nextOdd = 0
+	}
+	if clusterSpec.BrokerCount < nextOdd {
+		violations = append(violations, fmt.Sprintf("brokerCount can only be decreased to the next odd count at a time, from %d to %d", oldSpec.BrokerCount, nextOdd))
+	}
+	if oldDiskSpace, oldErr := resource.ParseQuantity(oldSpec.Resources.DiskSpace); err == nil && oldErr == nil && diskSpace.Cmp(oldDiskSpace) < 0 {
+		violations = append(violations, fmt.Sprintf("resources.diskSpace can't be decreased from %s to %s", oldDiskSpace.String(), diskSpace.String()))
+	}
+	if clusterSpec.StorageClass != oldSpec.StorageClass {
+		violations = append(violations, fmt.Sprintf("storageClass can't be changed from %q after creation", oldSpec.StorageClass))
+	}
+	return violations
+}
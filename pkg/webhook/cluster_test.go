@@ -0,0 +1,135 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+func clusterWith(brokerCount int32, resources spec.ResourceSpec) *spec.ZookeeperCluster {
+	return &spec.ZookeeperCluster{
+		Spec: spec.ZookeeperClusterSpec{
+			BrokerCount: brokerCount,
+			Resources:   resources,
+		},
+	}
+}
+
+func TestDefaultCluster(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources spec.ResourceSpec
+		patch     string
+	}{
+		{
+			name:  "defaults",
+			patch: `[{"op":"add","path":"/spec/resources","value":{"memory":"200Mi","diskSpace":"100Mi","cpu":"500m","jvmHeap":"100Mi"}}]`,
+		},
+		{
+			name:      "heap from memory",
+			resources: spec.ResourceSpec{Memory: "1Gi", DiskSpace: "1Gi", CPU: "1"},
+			patch:     `[{"op":"add","path":"/spec/resources","value":{"memory":"1Gi","diskSpace":"1Gi","cpu":"1","jvmHeap":"512Mi"}}]`,
+		},
+		{
+			name:      "all set",
+			resources: spec.ResourceSpec{Memory: "1Gi", DiskSpace: "1Gi", CPU: "1", JvmHeap: "768Mi"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			patch, err := defaultCluster(clusterWith(3, test.resources))
+			if err != nil {
+				t.Fatalf("defaultCluster: %v", err)
+			}
+			if string(patch) != test.patch {
+				t.Errorf("got patch %s, want %s", patch, test.patch)
+			}
+		})
+	}
+}
+
+func TestValidateCluster(t *testing.T) {
+	small := spec.ResourceSpec{Memory: "200Mi", DiskSpace: "100Mi", JvmHeap: "512M"}
+	tests := []struct {
+		name       string
+		cluster    *spec.ZookeeperCluster
+		oldCluster *spec.ZookeeperCluster
+		violations []string
+	}{
+		{
+			name:    "valid",
+			cluster: clusterWith(3, spec.ResourceSpec{}),
+		},
+		{
+			name:       "even brokerCount",
+			cluster:    clusterWith(4, spec.ResourceSpec{}),
+			violations: []string{"brokerCount must be odd to tolerate failures with a quorum, got 4"},
+		},
+		{
+			name:       "heap exceeds memory",
+			cluster:    clusterWith(3, small),
+			violations: []string{"resources.jvmHeap 512M exceeds resources.memory 200Mi"},
+		},
+		{
+			name:       "unchanged even brokerCount",
+			cluster:    clusterWith(4, spec.ResourceSpec{Memory: "1Gi"}),
+			oldCluster: clusterWith(4, spec.ResourceSpec{}),
+		},
+		{
+			name:       "changed to even brokerCount",
+			cluster:    clusterWith(4, spec.ResourceSpec{}),
+			oldCluster: clusterWith(3, spec.ResourceSpec{}),
+			violations: []string{"brokerCount must be odd to tolerate failures with a quorum, got 4"},
+		},
+		{
+			name:       "unchanged heap exceeding memory",
+			cluster:    clusterWith(5, small),
+			oldCluster: clusterWith(3, small),
+		},
+		{
+			name:       "memory decreased below heap",
+			cluster:    clusterWith(3, small),
+			oldCluster: clusterWith(3, spec.ResourceSpec{Memory: "1Gi", DiskSpace: "100Mi", JvmHeap: "512M"}),
+			violations: []string{"resources.jvmHeap 512M exceeds resources.memory 200Mi"},
+		},
+		{
+			name:       "brokerCount decreased by two",
+			cluster:    clusterWith(3, spec.ResourceSpec{}),
+			oldCluster: clusterWith(5, spec.ResourceSpec{}),
+		},
+		{
+			name:       "even brokerCount decreased by one",
+			cluster:    clusterWith(3, spec.ResourceSpec{}),
+			oldCluster: clusterWith(4, spec.ResourceSpec{}),
+		},
+		{
+			name:       "brokerCount decreased by four",
+			cluster:    clusterWith(3, spec.ResourceSpec{}),
+			oldCluster: clusterWith(7, spec.ResourceSpec{}),
+			violations: []string{"brokerCount can only be decreased to the next odd count at a time, from 7 to 5"},
+		},
+		{
+			name:       "diskSpace decreased",
+			cluster:    clusterWith(3, spec.ResourceSpec{DiskSpace: "50Mi"}),
+			oldCluster: clusterWith(3, spec.ResourceSpec{}),
+			violations: []string{"resources.diskSpace can't be decreased from 100Mi to 50Mi"},
+		},
+		{
+			name: "zkutil probes without image",
+			cluster: &spec.ZookeeperCluster{Spec: spec.ZookeeperClusterSpec{
+				BrokerCount: 3,
+				Probes:      &spec.ProbesSpec{Mode: spec.ProbeModeZkutil},
+			}},
+			violations: []string{"probes.image is required by the zkutil probe mode"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			violations := validateCluster(test.cluster, test.oldCluster)
+			if strings.Join(violations, "\n") != strings.Join(test.violations, "\n") {
+				t.Errorf("got %q, want %q", violations, test.violations)
+			}
+		})
+	}
+}
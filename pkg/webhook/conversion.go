@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+	"github.com/liwang-pivotal/zookeeper-operator/spec/v1beta1"
+)
+
+// serveConversion converts ZookeeperClusters between v1 and v1beta1 for the API server.
+func serveConversion(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	review := apiextensionsv1beta1.ConversionReview{}
+	err = json.Unmarshal(body, &review)
+	if err != nil || review.Request == nil {
+		http.Error(w, fmt.Sprintf("invalid ConversionReview: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	response := &apiextensionsv1beta1.ConversionResponse{
+		UID:    review.Request.UID,
+		Result: metav1.Status{Status: metav1.StatusSuccess},
+	}
+	for _, object := range review.Request.Objects {
+		converted, err := convert(object.Raw, review.Request.DesiredAPIVersion)
+		if err != nil {
+			logger.WithField("error", err).Warn("Cant convert object")
+			response.ConvertedObjects = nil
+			response.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+			break
+		}
+		response.ConvertedObjects = append(response.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+	review.Request = nil
+	review.Response = response
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
+
+// convert converts a serialized ZookeeperCluster into desiredAPIVersion.
+func convert(raw []byte, desiredAPIVersion string) ([]byte, error) {
+	typeMeta := metav1.TypeMeta{}
+	err := json.Unmarshal(raw, &typeMeta)
+	if err != nil {
+		return nil, err
+	}
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+
+	switch {
+	case typeMeta.APIVersion == spec.SchemeGroupVersion.String() && desiredAPIVersion == v1beta1.SchemeGroupVersion.String():
+		in, out := &spec.ZookeeperCluster{}, &v1beta1.ZookeeperCluster{}
+		if err = json.Unmarshal(raw, in); err != nil {
+			return nil, err
+		}
+		if err = v1beta1.ConvertFromV1(in, out); err != nil {
+			return nil, err
+		}
+		return json.Marshal(out)
+
+	case typeMeta.APIVersion == v1beta1.SchemeGroupVersion.String() && desiredAPIVersion == spec.SchemeGroupVersion.String():
+		in, out := &v1beta1.ZookeeperCluster{}, &spec.ZookeeperCluster{}
+		if err = json.Unmarshal(raw, in); err != nil {
+			return nil, err
+		}
+		if err = v1beta1.ConvertToV1(in, out); err != nil {
+			return nil, err
+		}
+		return json.Marshal(out)
+	}
+	return nil, fmt.Errorf("cant convert %s %s to %s", typeMeta.APIVersion, typeMeta.Kind, desiredAPIVersion)
+}
@@ -0,0 +1,87 @@
+package webhook
+
+import (
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+// register creates or updates the webhook configurations that route ZookeeperCluster
+// admission requests to the operator.
+func register(config Config, client kube.Kubernetes, caBundle []byte) error {
+	failurePolicy := admissionregistrationv1beta1.Fail
+	sideEffects := admissionregistrationv1beta1.SideEffectClassNone
+	// requests for other served versions are converted to v1 before they are sent
+	matchPolicy := admissionregistrationv1beta1.Equivalent
+	rules := []admissionregistrationv1beta1.RuleWithOperations{{
+		Operations: []admissionregistrationv1beta1.OperationType{
+			admissionregistrationv1beta1.Create,
+			admissionregistrationv1beta1.Update,
+		},
+		Rule: admissionregistrationv1beta1.Rule{
+			APIGroups:   []string{spec.CRDGroupName},
+			APIVersions: []string{spec.CRDVersion},
+			Resources:   []string{spec.CRDRessourcePlural},
+		},
+	}}
+	clientConfig := func(path string) admissionregistrationv1beta1.WebhookClientConfig {
+		return admissionregistrationv1beta1.WebhookClientConfig{
+			Service: &admissionregistrationv1beta1.ServiceReference{
+				Namespace: config.ServiceNamespace,
+				Name:      config.ServiceName,
+				Path:      &path,
+			},
+			CABundle: caBundle,
+		}
+	}
+	meta := metav1.ObjectMeta{Name: config.ConfigurationName}
+
+	validating := &admissionregistrationv1beta1.ValidatingWebhookConfiguration{
+		ObjectMeta: meta,
+		Webhooks: []admissionregistrationv1beta1.ValidatingWebhook{{
+			Name:          "validate." + spec.CRDRessourcePlural + "." + spec.CRDGroupName,
+			ClientConfig:  clientConfig(validatePath),
+			Rules:         rules,
+			FailurePolicy: &failurePolicy,
+			MatchPolicy:   &matchPolicy,
+			SideEffects:   &sideEffects,
+		}},
+	}
+	validatingClient := client.Client.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations()
+	existingValidating, err := validatingClient.Get(meta.Name, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		_, err = validatingClient.Create(validating)
+	case err == nil:
+		existingValidating.Webhooks = validating.Webhooks
+		_, err = validatingClient.Update(existingValidating)
+	}
+	if err != nil {
+		return err
+	}
+
+	mutating := &admissionregistrationv1beta1.MutatingWebhookConfiguration{
+		ObjectMeta: meta,
+		Webhooks: []admissionregistrationv1beta1.MutatingWebhook{{
+			Name:          "default." + spec.CRDRessourcePlural + "." + spec.CRDGroupName,
+			ClientConfig:  clientConfig(mutatePath),
+			Rules:         rules,
+			FailurePolicy: &failurePolicy,
+			MatchPolicy:   &matchPolicy,
+			SideEffects:   &sideEffects,
+		}},
+	}
+	mutatingClient := client.Client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
+	existingMutating, err := mutatingClient.Get(meta.Name, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		_, err = mutatingClient.Create(mutating)
+	case err == nil:
+		existingMutating.Webhooks = mutating.Webhooks
+		_, err = mutatingClient.Update(existingMutating)
+	}
+	return err
+}
@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/liwang-pivotal/zookeeper-operator/pkg/kube"
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+const (
+	validatePath = "/validate"
+	mutatePath   = "/mutate"
+	convertPath  = "/convert"
+
+	// shutdownTimeout bounds how long Run waits for requests in flight once ctx is done
+	shutdownTimeout = 5 * time.Second
+)
+
+var (
+	logger = log.WithFields(log.Fields{
+		"package": "webhook",
+	})
+)
+
+// Config configures the admission webhook server of the operator.
+type Config struct {
+	ListenAddress string
+	// ServiceName and ServiceNamespace of the Service in front of the operator pods.
+	ServiceName      string
+	ServiceNamespace string
+	// CertificateSecret holds the self-managed serving certificate.
+	CertificateSecret string
+	// ConfigurationName of the registered Validating- and MutatingWebhookConfiguration.
+	ConfigurationName string
+}
+
+// Server serves the admission and conversion webhooks of the operator.
+type Server struct {
+	config   Config
+	cert     tls.Certificate
+	caBundle []byte
+}
+
+// New provisions the serving certificate and registers the admission webhooks with the API server.
+func New(config Config, client kube.Kubernetes) (*Server, error) {
+	dnsName := fmt.Sprintf("%s.%s.svc", config.ServiceName, config.ServiceNamespace)
+	cert, caBundle, err := ensureCertificate(client, config.ServiceNamespace, config.CertificateSecret, dnsName)
+	if err != nil {
+		return nil, fmt.Errorf("cant provision webhook certificate: %v", err)
+	}
+	err = register(config, client, caBundle)
+	if err != nil {
+		return nil, fmt.Errorf("cant register webhooks: %v", err)
+	}
+	return &Server{
+		config:   config,
+		cert:     cert,
+		caBundle: caBundle,
+	}, nil
+}
+
+// ConversionWebhook returns the client config the API server reaches the conversion webhook with.
+func (s *Server) ConversionWebhook() *apiextensionsv1beta1.WebhookClientConfig {
+	path := convertPath
+	port := int32(443)
+	return &apiextensionsv1beta1.WebhookClientConfig{
+		Service: &apiextensionsv1beta1.ServiceReference{
+			Namespace: s.config.ServiceNamespace,
+			Name:      s.config.ServiceName,
+			Path:      &path,
+			Port:      &port,
+		},
+		CABundle: s.caBundle,
+	}
+}
+
+// Run serves admission and conversion requests for ZookeeperClusters until ctx is done or
+// the server fails.
+func (s *Server) Run(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(validatePath, serve(validate))
+	mux.HandleFunc(mutatePath, serve(mutate))
+	mux.HandleFunc(convertPath, serveConversion)
+	server := &http.Server{
+		Addr:      s.config.ListenAddress,
+		Handler:   mux,
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{s.cert}},
+	}
+	logger.WithFields(log.Fields{
+		"method":  "Run",
+		"address": s.config.ListenAddress,
+	}).Info("Serving webhooks")
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+	err := server.ListenAndServeTLS("", "")
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// serve decodes an AdmissionReview, lets admit decide and writes the response back.
+func serve(admit func(*admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		review := admissionv1beta1.AdmissionReview{}
+		err = json.Unmarshal(body, &review)
+		if err != nil || review.Request == nil {
+			http.Error(w, fmt.Sprintf("invalid AdmissionReview: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		response := admit(review.Request)
+		response.UID = review.Request.UID
+		review.Response = response
+		review.Request = nil
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(review)
+	}
+}
+
+func validate(request *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	cluster := &spec.ZookeeperCluster{}
+	err := json.Unmarshal(request.Object.Raw, cluster)
+	if err != nil {
+		return deny(err.Error())
+	}
+	var oldCluster *spec.ZookeeperCluster
+	if request.Operation == admissionv1beta1.Update {
+		oldCluster = &spec.ZookeeperCluster{}
+		err = json.Unmarshal(request.OldObject.Raw, oldCluster)
+		if err != nil {
+			return deny(err.Error())
+		}
+	}
+
+	violations := validateCluster(cluster, oldCluster)
+	if len(violations) > 0 {
+		logger.WithFields(log.Fields{
+			"clusterName": request.Name,
+			"namespace":   request.Namespace,
+			"violations":  violations,
+		}).Info("Rejected cluster")
+		return deny(strings.Join(violations, "; "))
+	}
+	return &admissionv1beta1.AdmissionResponse{Allowed: true}
+}
+
+func mutate(request *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	cluster := &spec.ZookeeperCluster{}
+	err := json.Unmarshal(request.Object.Raw, cluster)
+	if err != nil {
+		return deny(err.Error())
+	}
+	patch, err := defaultCluster(cluster)
+	if err != nil {
+		return deny(err.Error())
+	}
+
+	response := &admissionv1beta1.AdmissionResponse{Allowed: true}
+	if patch != nil {
+		patchType := admissionv1beta1.PatchTypeJSONPatch
+		response.Patch = patch
+		response.PatchType = &patchType
+	}
+	return response
+}
+
+func deny(message string) *admissionv1beta1.AdmissionResponse {
+	return &admissionv1beta1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonInvalid,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,158 @@
+package zookeeper
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/samuel/go-zookeeper/zk"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+const (
+	digestScheme   = "digest"
+	passwordLength = 24
+)
+
+var permissions = map[string]int32{
+	"read":   zk.PermRead,
+	"write":  zk.PermWrite,
+	"create": zk.PermCreate,
+	"delete": zk.PermDelete,
+	"admin":  zk.PermAdmin,
+	"all":    zk.PermAll,
+}
+
+// GeneratePassword returns a random, URL safe password.
+func GeneratePassword() (string, error) {
+	b := make([]byte, passwordLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Digest returns the digest ACL id of a user, "username:base64(sha1(username:password))".
+func Digest(username, password string) string {
+	h := sha1.Sum([]byte(username + ":" + password))
+	return username + ":" + base64.StdEncoding.EncodeToString(h[:])
+}
+
+// ParsePermissions converts permission names into a zookeeper permission mask.
+func ParsePermissions(names []string) (int32, error) {
+	var perms int32
+	for _, name := range names {
+		perm, ok := permissions[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown zookeeper permission %q", name)
+		}
+		perms |= perm
+	}
+	return perms, nil
+}
+
+// GrantDigestACL adds or replaces the ACL entry of the digest identity on every listed znode.
+func (c *Client) GrantDigestACL(digest string, acls []spec.ZnodeACL) error {
+	for _, acl := range acls {
+		perms, err := ParsePermissions(acl.Permissions)
+		if err != nil {
+			return err
+		}
+		err = c.setACLEntry(acl.Path, zk.ACL{Perms: perms, Scheme: digestScheme, ID: digest})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevokeDigestACL removes the ACL entry of the digest identity from every listed znode.
+// Znodes that no longer exist are ignored.
+func (c *Client) RevokeDigestACL(digest string, acls []spec.ZnodeACL) error {
+	for _, acl := range acls {
+		err := c.removeACLEntry(acl.Path, digestScheme, digest)
+		if err != nil && err != zk.ErrNoNode {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) setACLEntry(path string, entry zk.ACL) error {
+	methodLogger := logger.WithFields(log.Fields{
+		"method": "setACLEntry",
+		"path":   path,
+		"scheme": entry.Scheme,
+	})
+
+	acls, stat, err := c.Conn.GetACL(path)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant get ACL of znode")
+		return err
+	}
+
+	updated := make([]zk.ACL, 0, len(acls)+1)
+	for _, acl := range acls {
+		if acl.Scheme == entry.Scheme && acl.ID == entry.ID {
+			if acl.Perms == entry.Perms {
+				methodLogger.Debug("ACL entry already present")
+				return nil
+			}
+			continue
+		}
+		updated = append(updated, acl)
+	}
+	updated = append(updated, entry)
+
+	_, err = c.Conn.SetACL(path, updated, stat.Aversion)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant set ACL of znode")
+		return err
+	}
+	methodLogger.Info("Granted ACL entry")
+	return nil
+}
+
+func (c *Client) removeACLEntry(path string, scheme, id string) error {
+	methodLogger := logger.WithFields(log.Fields{
+		"method": "removeACLEntry",
+		"path":   path,
+		"scheme": scheme,
+	})
+
+	acls, stat, err := c.Conn.GetACL(path)
+	if err != nil {
+		return err
+	}
+
+	updated := make([]zk.ACL, 0, len(acls))
+	for _, acl := range acls {
+		if acl.Scheme == scheme && acl.ID == id {
+			continue
+		}
+		updated = append(updated, acl)
+	}
+	if len(updated) == len(acls) {
+		methodLogger.Debug("ACL entry not present")
+		return nil
+	}
+	if len(updated) == 0 {
+		// zookeeper rejects an empty ACL, keep the znode closed to everyone but the operator
+		updated, err = c.operatorACL()
+		if err != nil {
+			methodLogger.WithField("error", err).Error("Cant remove the last ACL entry of znode")
+			return err
+		}
+	}
+
+	_, err = c.Conn.SetACL(path, updated, stat.Aversion)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant set ACL of znode")
+		return err
+	}
+	methodLogger.Info("Revoked ACL entry")
+	return nil
+}
@@ -0,0 +1,239 @@
+package zookeeper
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/samuel/go-zookeeper/zk"
+	log "github.com/sirupsen/logrus"
+)
+
+// systemRoot holds zookeeper's own quota and config nodes, which are never exported.
+const systemRoot = "/zookeeper"
+
+// Znode is a single record of a logical dump, one JSON document per line.
+type Znode struct {
+	Path           string `json:"path"`
+	Data           []byte `json:"data,omitempty"`
+	ACL            []ACL  `json:"acl"`
+	Ephemeral      bool   `json:"ephemeral,omitempty"`
+	EphemeralOwner int64  `json:"ephemeralOwner,omitempty"`
+}
+
+// ACL is a portable ACL entry with named permissions.
+type ACL struct {
+	Scheme      string   `json:"scheme"`
+	ID          string   `json:"id"`
+	Permissions []string `json:"permissions"`
+}
+
+type ImportOptions struct {
+	// Overwrite replaces data and ACL of znodes that already exist.
+	Overwrite bool
+	// IncludeEphemeral recreates ephemeral znodes as persistent ones instead of skipping them.
+	IncludeEphemeral bool
+}
+
+// Export walks the tree below root and writes every znode to w, parents before children.
+func (c *Client) Export(root string, w io.Writer) (int, error) {
+	encoder := json.NewEncoder(w)
+	count := 0
+	err := c.walk(root, func(znode *Znode) error {
+		count++
+		return encoder.Encode(znode)
+	})
+	return count, err
+}
+
+func (c *Client) walk(p string, visit func(*Znode) error) error {
+	if p == systemRoot {
+		return nil
+	}
+
+	data, stat, err := c.Conn.Get(p)
+	if err == zk.ErrNoNode {
+		// deleted while walking
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	acls, _, err := c.Conn.GetACL(p)
+	if err != nil && err != zk.ErrNoNode {
+		return err
+	}
+
+	znode := &Znode{
+		Path:           p,
+		Data:           data,
+		ACL:            fromZkACL(acls),
+		Ephemeral:      stat.EphemeralOwner != 0,
+		EphemeralOwner: stat.EphemeralOwner,
+	}
+	if p != "/" {
+		if err := visit(znode); err != nil {
+			return err
+		}
+	}
+
+	children, _, err := c.Conn.Children(p)
+	if err == zk.ErrNoNode {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	sort.Strings(children)
+	for _, child := range children {
+		if err := c.walk(path.Join(p, child), visit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Import replays a dump written by Export. Parents missing from the dump are created empty.
+// All znodes are first created with an ACL that only gives the operator access, a restrictive
+// ACL would otherwise keep the import from creating the children of a znode. The recorded ACLs
+// are applied once the whole tree exists, children before parents. Parents missing from the
+// dump keep the operator ACL.
+func (c *Client) Import(r io.Reader, options ImportOptions) (int, error) {
+	methodLogger := logger.WithFields(log.Fields{"method": "Import"})
+
+	operatorACL, err := c.operatorACL()
+	if err != nil {
+		return 0, err
+	}
+	created := make(map[string]bool)
+	var pending []Znode
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	count := 0
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var znode Znode
+		if err := json.Unmarshal(scanner.Bytes(), &znode); err != nil {
+			return count, err
+		}
+		if znode.Ephemeral && !options.IncludeEphemeral {
+			methodLogger.WithField("path", znode.Path).Debug("Skipping ephemeral znode")
+			continue
+		}
+		if _, err := toZkACL(znode.ACL); err != nil {
+			return count, err
+		}
+		written, err := c.importZnode(znode.Path, znode.Data, operatorACL, created, options.Overwrite)
+		if err != nil {
+			return count, err
+		}
+		if written {
+			pending = append(pending, znode)
+		}
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return count, err
+	}
+
+	// the operator keeps access to a parent until the ACLs below it are set
+	sort.SliceStable(pending, func(i, j int) bool {
+		return strings.Count(pending[i].Path, "/") > strings.Count(pending[j].Path, "/")
+	})
+	for _, znode := range pending {
+		acl, _ := toZkACL(znode.ACL)
+		if _, err := c.Conn.SetACL(znode.Path, acl, -1); err != nil {
+			methodLogger.WithFields(log.Fields{"path": znode.Path, "error": err}).Error("Cant set ACL of znode")
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// importZnode creates a znode with acl, or sets its data if it existed and overwrite is set or it
+// is a parent this import created empty. It reports whether the znode was written.
+func (c *Client) importZnode(p string, data []byte, acl []zk.ACL, created map[string]bool, overwrite bool) (bool, error) {
+	if err := c.ensureParents(p, acl, created); err != nil {
+		return false, err
+	}
+	_, err := c.Conn.Create(p, data, 0, acl)
+	if err == nil {
+		created[p] = true
+		return true, nil
+	}
+	if err != zk.ErrNodeExists {
+		return false, err
+	}
+	if !overwrite && !created[p] {
+		return false, nil
+	}
+	if _, err = c.Conn.Set(p, data, -1); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *Client) ensureParents(p string, acl []zk.ACL, created map[string]bool) error {
+	parent := path.Dir(p)
+	if parent == "/" {
+		return nil
+	}
+	exists, _, err := c.Conn.Exists(parent)
+	if err != nil || exists {
+		return err
+	}
+	if err := c.ensureParents(parent, acl, created); err != nil {
+		return err
+	}
+	_, err = c.Conn.Create(parent, nil, 0, acl)
+	if err == zk.ErrNodeExists {
+		return nil
+	}
+	if err == nil {
+		created[parent] = true
+	}
+	return err
+}
+
+func fromZkACL(acls []zk.ACL) []ACL {
+	result := make([]ACL, 0, len(acls))
+	for _, acl := range acls {
+		result = append(result, ACL{
+			Scheme:      acl.Scheme,
+			ID:          acl.ID,
+			Permissions: permissionNames(acl.Perms),
+		})
+	}
+	return result
+}
+
+func toZkACL(acls []ACL) ([]zk.ACL, error) {
+	if len(acls) == 0 {
+		return zk.WorldACL(zk.PermAll), nil
+	}
+	result := make([]zk.ACL, 0, len(acls))
+	for _, acl := range acls {
+		perms, err := ParsePermissions(acl.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, zk.ACL{Perms: perms, Scheme: acl.Scheme, ID: acl.ID})
+	}
+	return result, nil
+}
+
+func permissionNames(perms int32) []string {
+	names := []string{}
+	for _, name := range []string{"read", "write", "create", "delete", "admin"} {
+		if perms&permissions[name] != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
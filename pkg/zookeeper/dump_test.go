@@ -0,0 +1,187 @@
+package zookeeper
+
+import (
+	"bytes"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+type fakeZnode struct {
+	data []byte
+	acl  []zk.ACL
+}
+
+// fakeConn is an in-memory tree that checks ACLs like ZooKeeper does for the digest
+// identities of its session. A super session may do anything.
+type fakeConn struct {
+	znodes map[string]*fakeZnode
+	ids    []string
+	super  bool
+}
+
+func newFakeConn(auth ...string) *fakeConn {
+	conn := &fakeConn{znodes: map[string]*fakeZnode{"/": {acl: zk.WorldACL(zk.PermAll)}}}
+	for _, credentials := range auth {
+		userPassword := strings.SplitN(credentials, ":", 2)
+		conn.ids = append(conn.ids, Digest(userPassword[0], userPassword[1]))
+	}
+	return conn
+}
+
+func (c *fakeConn) allowed(znode *fakeZnode, perm int32) bool {
+	if c.super {
+		return true
+	}
+	for _, acl := range znode.acl {
+		if acl.Perms&perm == 0 {
+			continue
+		}
+		if acl.Scheme == "world" && acl.ID == "anyone" {
+			return true
+		}
+		for _, id := range c.ids {
+			if acl.Scheme == digestScheme && acl.ID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *fakeConn) Get(p string) ([]byte, *zk.Stat, error) {
+	znode, ok := c.znodes[p]
+	if !ok {
+		return nil, nil, zk.ErrNoNode
+	}
+	if !c.allowed(znode, zk.PermRead) {
+		return nil, nil, zk.ErrNoAuth
+	}
+	return znode.data, &zk.Stat{}, nil
+}
+
+func (c *fakeConn) Children(p string) ([]string, *zk.Stat, error) {
+	znode, ok := c.znodes[p]
+	if !ok {
+		return nil, nil, zk.ErrNoNode
+	}
+	if !c.allowed(znode, zk.PermRead) {
+		return nil, nil, zk.ErrNoAuth
+	}
+	var children []string
+	for child := range c.znodes {
+		if child != "/" && path.Dir(child) == p {
+			children = append(children, path.Base(child))
+		}
+	}
+	return children, &zk.Stat{}, nil
+}
+
+func (c *fakeConn) Exists(p string) (bool, *zk.Stat, error) {
+	_, ok := c.znodes[p]
+	return ok, &zk.Stat{}, nil
+}
+
+func (c *fakeConn) Create(p string, data []byte, flags int32, acl []zk.ACL) (string, error) {
+	parent, ok := c.znodes[path.Dir(p)]
+	if !ok {
+		return "", zk.ErrNoNode
+	}
+	if !c.allowed(parent, zk.PermCreate) {
+		return "", zk.ErrNoAuth
+	}
+	if _, ok := c.znodes[p]; ok {
+		return "", zk.ErrNodeExists
+	}
+	c.znodes[p] = &fakeZnode{data: data, acl: acl}
+	return p, nil
+}
+
+func (c *fakeConn) Set(p string, data []byte, version int32) (*zk.Stat, error) {
+	znode, ok := c.znodes[p]
+	if !ok {
+		return nil, zk.ErrNoNode
+	}
+	if !c.allowed(znode, zk.PermWrite) {
+		return nil, zk.ErrNoAuth
+	}
+	znode.data = data
+	return &zk.Stat{}, nil
+}
+
+func (c *fakeConn) GetACL(p string) ([]zk.ACL, *zk.Stat, error) {
+	znode, ok := c.znodes[p]
+	if !ok {
+		return nil, nil, zk.ErrNoNode
+	}
+	return znode.acl, &zk.Stat{}, nil
+}
+
+func (c *fakeConn) SetACL(p string, acl []zk.ACL, version int32) (*zk.Stat, error) {
+	znode, ok := c.znodes[p]
+	if !ok {
+		return nil, zk.ErrNoNode
+	}
+	if !c.allowed(znode, zk.PermAdmin) {
+		return nil, zk.ErrNoAuth
+	}
+	znode.acl = acl
+	return &zk.Stat{}, nil
+}
+
+func (c *fakeConn) Close() {}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	alice := zk.DigestACL(zk.PermAll, "alice", "secret")
+	source := newFakeConn()
+	source.super = true
+	source.znodes["/app"] = &fakeZnode{data: []byte("app"), acl: alice}
+	source.znodes["/app/config"] = &fakeZnode{data: []byte("config"), acl: zk.WorldACL(zk.PermRead)}
+	source.znodes["/app/config/limits"] = &fakeZnode{data: []byte("limits"), acl: alice}
+	source.znodes["/open"] = &fakeZnode{data: []byte("open"), acl: zk.WorldACL(zk.PermAll)}
+
+	var dump bytes.Buffer
+	exported, err := (&Client{Conn: source}).Export("/", &dump)
+	if err != nil || exported != 4 {
+		t.Fatalf("Export returned %d, %v, want 4 znodes", exported, err)
+	}
+
+	// /app only lets alice create children, the operator has to set it after its subtree
+	target := newFakeConn("operator:password")
+	imported, err := (&Client{Conn: target, auth: "operator:password"}).Import(&dump, ImportOptions{})
+	if err != nil || imported != 4 {
+		t.Fatalf("Import returned %d, %v, want 4 znodes", imported, err)
+	}
+	for p, want := range source.znodes {
+		got, ok := target.znodes[p]
+		if !ok {
+			t.Errorf("%s was not imported", p)
+			continue
+		}
+		if !bytes.Equal(got.data, want.data) || !reflect.DeepEqual(got.acl, want.acl) {
+			t.Errorf("%s was imported as %q %v, want %q %v", p, got.data, got.acl, want.data, want.acl)
+		}
+	}
+}
+
+func TestImportRestrictsMissingParents(t *testing.T) {
+	dump := strings.NewReader(`{"path":"/missing/child","data":"Y2hpbGQ=","acl":[{"scheme":"world","id":"anyone","permissions":["read"]}]}` + "\n")
+	target := newFakeConn("operator:password")
+	if _, err := (&Client{Conn: target, auth: "operator:password"}).Import(dump, ImportOptions{}); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	operator := zk.DigestACL(zk.PermAll, "operator", "password")
+	if parent := target.znodes["/missing"]; parent == nil || !reflect.DeepEqual(parent.acl, operator) {
+		t.Errorf("missing parent was created as %+v, want the operator ACL %v", parent, operator)
+	}
+	if child := target.znodes["/missing/child"]; child == nil || string(child.data) != "child" || !reflect.DeepEqual(child.acl, zk.WorldACL(zk.PermRead)) {
+		t.Errorf("child was imported as %+v", child)
+	}
+
+	if _, err := (&Client{Conn: newFakeConn()}).Import(strings.NewReader(""), ImportOptions{}); err == nil {
+		t.Error("Import returned no error without operator credentials")
+	}
+}
@@ -0,0 +1,80 @@
+package zookeeper
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+	"time"
+)
+
+// ErrNotServing is returned by ServerMode for a member that runs but doesn't serve requests,
+// because it lost the quorum or hasn't synced with the leader yet.
+var ErrNotServing = errors.New("zookeeper is not currently serving requests")
+
+// headlessService publishes the DNS names of the members.
+const headlessService = "zk-headless"
+
+// Resolves reports whether the DNS name of member resolves, relative to the search domains of
+// the namespace of the caller.
+func Resolves(member string) bool {
+	_, err := net.LookupHost(member + "." + headlessService)
+	return err == nil
+}
+
+// ServerMode asks the member at server for its mode with the srvr command, the only
+// four letter word ZooKeeper 3.5 allows by default. It returns leader, follower, observer or
+// standalone.
+func ServerMode(server string, timeout time.Duration) (string, error) {
+	conn, err := net.DialTimeout("tcp", server, timeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if _, err := conn.Write([]byte("srvr")); err != nil {
+		return "", err
+	}
+	response, err := ioutil.ReadAll(conn)
+	if err != nil {
+		return "", err
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(response)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "This ZooKeeper instance is not currently serving requests") {
+			return "", ErrNotServing
+		}
+		if strings.HasPrefix(line, "Mode: ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "Mode: ")), nil
+		}
+	}
+	return "", fmt.Errorf("no mode in srvr response of %s: %q", server, response)
+}
+
+// Live returns nil if the member at server answers srvr, even if it doesn't serve requests.
+func Live(server string, timeout time.Duration) error {
+	_, err := ServerMode(server, timeout)
+	if err == ErrNotServing {
+		return nil
+	}
+	return err
+}
+
+// Ready returns nil if the member at server serves requests as part of the quorum. Members that
+// lost the quorum or haven't synced with the leader yet are not ready.
+func Ready(server string, timeout time.Duration) error {
+	mode, err := ServerMode(server, timeout)
+	if err != nil {
+		return err
+	}
+	switch mode {
+	case "leader", "follower", "observer", "standalone":
+		return nil
+	}
+	return fmt.Errorf("member is in mode %s", mode)
+}
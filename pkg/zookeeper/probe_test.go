@@ -0,0 +1,80 @@
+package zookeeper
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// serveSrvr answers every srvr command sent to the returned listener with response.
+func serveSrvr(t *testing.T, response string) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			command := make([]byte, 4)
+			conn.Read(command)
+			if string(command) == "srvr" {
+				conn.Write([]byte(response))
+			}
+			conn.Close()
+		}
+	}()
+	return listener
+}
+
+func serverMode(t *testing.T, response string) (string, error) {
+	listener := serveSrvr(t, response)
+	defer listener.Close()
+	return ServerMode(listener.Addr().String(), time.Second)
+}
+
+func TestServerMode(t *testing.T) {
+	tests := []struct {
+		response string
+		mode     string
+		err      error
+	}{
+		{
+			response: "Zookeeper version: 3.4.10\nLatency min/avg/max: 0/0/0\nZxid: 0x100000002\nMode: follower\nNode count: 4\n",
+			mode:     "follower",
+		},
+		{
+			response: "Zookeeper version: 3.5.8\nMode: leader\nNode count: 4\nProposal sizes last/min/max: -1/-1/-1\n",
+			mode:     "leader",
+		},
+		{
+			response: "This ZooKeeper instance is not currently serving requests\n",
+			err:      ErrNotServing,
+		},
+	}
+	for _, test := range tests {
+		mode, err := serverMode(t, test.response)
+		if mode != test.mode || err != test.err {
+			t.Errorf("ServerMode returned %q, %v for %q, want %q, %v", mode, err, test.response, test.mode, test.err)
+		}
+	}
+
+	if _, err := serverMode(t, "srvr is not executed because it is not in the whitelist.\n"); err == nil {
+		t.Error("ServerMode returned no error for a response without mode")
+	}
+}
+
+func TestReadyWithoutQuorum(t *testing.T) {
+	listener := serveSrvr(t, "This ZooKeeper instance is not currently serving requests\n")
+	defer listener.Close()
+	server := listener.Addr().String()
+
+	if err := Ready(server, time.Second); err != ErrNotServing {
+		t.Errorf("Ready returned %v for a member without quorum, want %v", err, ErrNotServing)
+	}
+	if err := Live(server, time.Second); err != nil {
+		t.Errorf("Live returned %v for a member without quorum, want nil", err)
+	}
+}
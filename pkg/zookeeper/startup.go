@@ -0,0 +1,57 @@
+package zookeeper
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	serverPort   = 2888
+	electionPort = 3888
+
+	resolveInterval = 2 * time.Second
+)
+
+// MyID returns the id of the member with the given host name, its ordinal plus one like
+// zkGenConfig.sh assigns it.
+func MyID(hostname string) (int, error) {
+	i := strings.LastIndex(hostname, "-")
+	ordinal, err := strconv.Atoi(hostname[i+1:])
+	if i < 0 || err != nil {
+		return 0, fmt.Errorf("host name %q doesn't end with the ordinal of a StatefulSet pod", hostname)
+	}
+	return ordinal + 1, nil
+}
+
+// ServerConfig returns the server lines of zoo.cfg for the members, the pods of the StatefulSet
+// in the order of their ordinals. The dynamic configuration of ZooKeeper 3.5 adds the role and
+// the client port.
+func ServerConfig(members []string, dynamic bool) string {
+	var config strings.Builder
+	for i, member := range members {
+		fmt.Fprintf(&config, "server.%d=%s.%s:%d:%d", i+1, member, headlessService, serverPort, electionPort)
+		if dynamic {
+			fmt.Fprintf(&config, ":participant;%d", clientPort)
+		}
+		config.WriteString("\n")
+	}
+	return config.String()
+}
+
+// WaitForDNS waits until the DNS names of all members resolve or ctx is done.
+func WaitForDNS(ctx context.Context, members []string) error {
+	for _, member := range members {
+		for !Resolves(member) {
+			logger.WithField("member", member).Info("Waiting for the DNS name of the member")
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("DNS name of %s doesn't resolve: %v", member, ctx.Err())
+			case <-time.After(resolveInterval):
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package zookeeper
+
+import "testing"
+
+func TestMyID(t *testing.T) {
+	if id, err := MyID("zk-2"); id != 3 || err != nil {
+		t.Errorf("MyID(zk-2) returned %d, %v, want 3", id, err)
+	}
+	if id, err := MyID("my-zk-0"); id != 1 || err != nil {
+		t.Errorf("MyID(my-zk-0) returned %d, %v, want 1", id, err)
+	}
+	if _, err := MyID("zookeeper"); err == nil {
+		t.Error("MyID returned no error for a host name without ordinal")
+	}
+}
+
+func TestServerConfig(t *testing.T) {
+	members := []string{"zk-0", "zk-1"}
+	static := "server.1=zk-0.zk-headless:2888:3888\nserver.2=zk-1.zk-headless:2888:3888\n"
+	if got := ServerConfig(members, false); got != static {
+		t.Errorf("static server config is\n%s\nwant\n%s", got, static)
+	}
+	dynamic := "server.1=zk-0.zk-headless:2888:3888:participant;2181\nserver.2=zk-1.zk-headless:2888:3888:participant;2181\n"
+	if got := ServerConfig(members, true); got != dynamic {
+		t.Errorf("dynamic server config is\n%s\nwant\n%s", got, dynamic)
+	}
+}
@@ -0,0 +1,50 @@
+package zookeeper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+const statsTimeout = 5 * time.Second
+
+// MemberStats returns the srvr statistics of every member of the cluster, indexed by ordinal.
+// Members that can't be reached have their Error set.
+func MemberStats(cluster spec.ZookeeperCluster) []*zk.ServerStats {
+	stats, _ := zk.FLWSrvr(Servers(cluster), statsTimeout)
+	return stats
+}
+
+// Leader returns the ordinal and statistics of the member that currently leads
+// the ensemble, or of the only member of a standalone cluster.
+func Leader(cluster spec.ZookeeperCluster) (int, *zk.ServerStats, error) {
+	for ordinal, stats := range MemberStats(cluster) {
+		if stats == nil || stats.Error != nil {
+			continue
+		}
+		if stats.Mode == zk.ModeLeader || stats.Mode == zk.ModeStandalone {
+			return ordinal, stats, nil
+		}
+	}
+	return -1, nil, fmt.Errorf("no leader found in cluster %s/%s", cluster.ObjectMeta.Namespace, cluster.ObjectMeta.Name)
+}
+
+// FormatZxid formats a zxid the way zookeeper prints it.
+func FormatZxid(zxid int64) string {
+	return fmt.Sprintf("0x%x", zxid)
+}
+
+// Zxid reassembles the last zxid a member has seen from its epoch and counter.
+func Zxid(stats *zk.ServerStats) int64 {
+	return int64(stats.Epoch)<<32 | int64(uint32(stats.Counter))
+}
+
+// ParseZxid parses a zxid formatted by FormatZxid.
+func ParseZxid(zxid string) (int64, error) {
+	return strconv.ParseInt(strings.TrimPrefix(zxid, "0x"), 16, 64)
+}
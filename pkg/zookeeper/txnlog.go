@@ -0,0 +1,56 @@
+package zookeeper
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	// txnLogMagic starts every transaction log, "ZKLG".
+	txnLogMagic = 0x5a4b4c47
+	// maxTxnSize bounds the entries read, far above the jute.maxbuffer of ZooKeeper.
+	maxTxnSize = 64 << 20
+)
+
+// LastLoggedZxid returns the zxid of the last transaction in a log.<zxid> file of ZooKeeper,
+// or -1 if it holds none. The files are preallocated with zeros, the log ends at the first
+// empty entry or at an entry that was only partly written.
+func LastLoggedZxid(txnLog io.Reader) (int64, error) {
+	reader := bufio.NewReader(txnLog)
+
+	// magic, version and database id
+	var header struct {
+		Magic   int32
+		Version int32
+		DBID    int64
+	}
+	if err := binary.Read(reader, binary.BigEndian, &header); err != nil {
+		return -1, fmt.Errorf("cant read transaction log header: %v", err)
+	}
+	if header.Magic != txnLogMagic {
+		return -1, fmt.Errorf("not a transaction log, magic is 0x%x", header.Magic)
+	}
+
+	last := int64(-1)
+	for {
+		// checksum, length, transaction and an end of record byte
+		var entry struct {
+			Checksum int64
+			Length   int32
+		}
+		if err := binary.Read(reader, binary.BigEndian, &entry); err != nil || entry.Length <= 0 || entry.Length > maxTxnSize {
+			return last, nil
+		}
+		txn := make([]byte, int(entry.Length)+1)
+		if _, err := io.ReadFull(reader, txn); err != nil {
+			return last, nil
+		}
+		// the transaction starts with the client id, the cxid and the zxid
+		if len(txn) < 21 || txn[len(txn)-1] != 'B' {
+			return last, nil
+		}
+		last = int64(binary.BigEndian.Uint64(txn[12:20]))
+	}
+}
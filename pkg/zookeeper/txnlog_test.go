@@ -0,0 +1,54 @@
+package zookeeper
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func writeAll(buffer *bytes.Buffer, values ...interface{}) {
+	for _, value := range values {
+		binary.Write(buffer, binary.BigEndian, value)
+	}
+}
+
+// txnLog writes a transaction log with one create per zxid, preallocated with zeros.
+func txnLog(zxids ...int64) []byte {
+	var log bytes.Buffer
+	writeAll(&log, int32(txnLogMagic), int32(2), int64(0))
+	for _, zxid := range zxids {
+		var txn bytes.Buffer
+		// client id, cxid, zxid, time, type and the create itself
+		writeAll(&txn, int64(1), int32(1), zxid, int64(0), int32(1))
+		txn.WriteString("/znode")
+		writeAll(&log, int64(0), int32(txn.Len()))
+		log.Write(txn.Bytes())
+		log.WriteByte('B')
+	}
+	log.Write(make([]byte, 512))
+	return log.Bytes()
+}
+
+func TestLastLoggedZxid(t *testing.T) {
+	zxid, err := LastLoggedZxid(bytes.NewReader(txnLog(0x100000001, 0x100000002, 0x100000003)))
+	if zxid != 0x100000003 || err != nil {
+		t.Errorf("LastLoggedZxid returned %s, %v, want 0x100000003", FormatZxid(zxid), err)
+	}
+
+	empty, err := LastLoggedZxid(bytes.NewReader(txnLog()))
+	if empty != -1 || err != nil {
+		t.Errorf("LastLoggedZxid of an empty log returned %d, %v, want -1", empty, err)
+	}
+
+	// an entry cut off by a crash doesn't count
+	log := txnLog(0x100000001, 0x100000002)
+	truncated := log[:len(log)-512-5]
+	zxid, err = LastLoggedZxid(bytes.NewReader(truncated))
+	if zxid != 0x100000001 || err != nil {
+		t.Errorf("LastLoggedZxid of a truncated log returned %s, %v, want 0x100000001", FormatZxid(zxid), err)
+	}
+
+	if _, err := LastLoggedZxid(bytes.NewReader(make([]byte, 64))); err == nil {
+		t.Error("LastLoggedZxid returned no error for a file without magic")
+	}
+}
@@ -0,0 +1,112 @@
+package zookeeper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+const (
+	clientPort     = 2181
+	sessionTimeout = 10 * time.Second
+	connectTimeout = 15 * time.Second
+)
+
+var (
+	logger = log.WithFields(log.Fields{
+		"package": "zookeeper",
+	})
+)
+
+// Conn is the part of a zk.Conn the client uses.
+type Conn interface {
+	Get(path string) ([]byte, *zk.Stat, error)
+	Children(path string) ([]string, *zk.Stat, error)
+	Exists(path string) (bool, *zk.Stat, error)
+	Create(path string, data []byte, flags int32, acl []zk.ACL) (string, error)
+	Set(path string, data []byte, version int32) (*zk.Stat, error)
+	GetACL(path string) ([]zk.ACL, *zk.Stat, error)
+	SetACL(path string, acl []zk.ACL, version int32) (*zk.Stat, error)
+	Close()
+}
+
+// Client is an administrative connection to a single ZooKeeper ensemble.
+type Client struct {
+	Conn    Conn
+	servers []string
+	auth    string
+}
+
+// Servers returns the client address of every member of the cluster, as published by the headless service.
+func Servers(cluster spec.ZookeeperCluster) []string {
+	servers := make([]string, 0, cluster.Spec.BrokerCount)
+	for i := int32(0); i < cluster.Spec.BrokerCount; i++ {
+		servers = append(servers, fmt.Sprintf("%s-%d.zk-headless.%s.svc.cluster.local:%d",
+			cluster.ObjectMeta.Name, i, cluster.ObjectMeta.Namespace, clientPort))
+	}
+	return servers
+}
+
+// New connects to the given servers and waits until a session is established.
+// auth are optional digest credentials ("user:password") added to the session.
+func New(servers []string, auth string) (*Client, error) {
+	methodLogger := logger.WithFields(log.Fields{
+		"method":  "New",
+		"servers": strings.Join(servers, ","),
+	})
+
+	conn, events, err := zk.Connect(servers, sessionTimeout)
+	if err != nil {
+		methodLogger.WithField("error", err).Error("Cant connect to zookeeper")
+		return nil, err
+	}
+	conn.SetLogger(methodLogger)
+
+	timeout := time.After(connectTimeout)
+	for connected := false; !connected; {
+		select {
+		case event := <-events:
+			if event.State == zk.StateHasSession {
+				connected = true
+			}
+		case <-timeout:
+			conn.Close()
+			return nil, fmt.Errorf("timed out connecting to zookeeper servers %v", servers)
+		}
+	}
+
+	if auth != "" {
+		err = conn.AddAuth("digest", []byte(auth))
+		if err != nil {
+			methodLogger.WithField("error", err).Error("Cant authenticate zookeeper session")
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	methodLogger.Debug("Connected to zookeeper")
+	return &Client{
+		Conn:    conn,
+		servers: servers,
+		auth:    auth,
+	}, nil
+}
+
+// operatorACL gives only the operator's own digest identity access, for znodes that must not
+// be left open. It needs the credentials the client authenticated with.
+func (c *Client) operatorACL() ([]zk.ACL, error) {
+	credentials := strings.SplitN(c.auth, ":", 2)
+	if len(credentials) != 2 {
+		return nil, fmt.Errorf("no operator credentials to restrict znodes to")
+	}
+	return zk.DigestACL(zk.PermAll, credentials[0], credentials[1]), nil
+}
+
+func (c *Client) Close() {
+	c.Conn.Close()
+}
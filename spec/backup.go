@@ -0,0 +1,106 @@
+package spec
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	BackupPhaseScheduled = "Scheduled"
+	BackupPhaseRunning   = "Running"
+	BackupPhaseCompleted = "Completed"
+	BackupPhaseFailed    = "Failed"
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ZookeeperBackup copies the latest snapshot and transaction logs of a cluster's
+// leader to object storage, either once or on a cron schedule.
+type ZookeeperBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec  ZookeeperBackupSpec  `json:"spec"`
+	State ZookeeperBackupState `json:"state,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ZookeeperBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ZookeeperBackup `json:"items"`
+}
+
+type ZookeeperBackupSpec struct {
+	// ClusterName is the name of the ZookeeperCluster in the same namespace.
+	ClusterName string `json:"clusterName" schema:"required,minLength=1"`
+	// Schedule is a cron expression, a backup without schedule runs once.
+	Schedule string `json:"schedule,omitempty"`
+	// Retention is the number of backups to keep, 0 keeps all of them.
+	Retention int32         `json:"retention,omitempty" schema:"minimum=0"`
+	Storage   BackupStorage `json:"storage" schema:"required"`
+}
+
+// BackupStorage selects exactly one backup target.
+type BackupStorage struct {
+	S3         *S3Storage         `json:"s3,omitempty"`
+	Filesystem *FilesystemStorage `json:"filesystem,omitempty"`
+}
+
+// S3Storage is an S3 compatible endpoint such as AWS S3 or MinIO.
+type S3Storage struct {
+	Endpoint string `json:"endpoint" schema:"required,minLength=1"`
+	Bucket   string `json:"bucket" schema:"required,minLength=1"`
+	Prefix   string `json:"prefix,omitempty"`
+	Region   string `json:"region,omitempty"`
+	Insecure bool   `json:"insecure,omitempty"`
+	// CredentialsSecret holds the keys accessKeyID and secretAccessKey.
+	CredentialsSecret string `json:"credentialsSecret" schema:"required,minLength=1"`
+}
+
+// FilesystemStorage is a directory on the operator's filesystem, meant for testing.
+type FilesystemStorage struct {
+	Path string `json:"path" schema:"required,pattern=^/"`
+}
+
+type ZookeeperBackupState struct {
+	Phase          string         `json:"phase,omitempty"`
+	LastBackupTime *metav1.Time   `json:"lastBackupTime,omitempty"`
+	Message        string         `json:"message,omitempty"`
+	Backups        []BackupRecord `json:"backups,omitempty"`
+}
+
+// BackupRecord describes a single backup archive that is still retained.
+type BackupRecord struct {
+	Name   string      `json:"name"`
+	Leader string      `json:"leader"`
+	Zxid   string      `json:"zxid"`
+	Size   int64       `json:"size"`
+	Time   metav1.Time `json:"time"`
+}
+
+type ZookeeperBackupWatchEvent struct {
+	Type      string          `json:"type"`
+	Object    ZookeeperBackup `json:"object"`
+	OldObject ZookeeperBackup `json:"oldObject"`
+}
+
+func PrintBackup(backup *ZookeeperBackup) string {
+	return fmt.Sprintf("%s/%s, APIVersion: %s, Kind: %s, Value: %#v", backup.ObjectMeta.Namespace, backup.ObjectMeta.Name, backup.APIVersion, backup.Kind, backup)
+}
+
+// Required to satisfy Object interface
+func (b *ZookeeperBackup) GetObjectKind() schema.ObjectKind {
+	return &b.TypeMeta
+}
+
+// Required to satisfy Object interface
+func (bl *ZookeeperBackupList) GetObjectKind() schema.ObjectKind {
+	return &bl.TypeMeta
+}
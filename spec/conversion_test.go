@@ -0,0 +1,75 @@
+package spec_test
+
+import (
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+	"github.com/liwang-pivotal/zookeeper-operator/spec/v1beta1"
+)
+
+// quantities are written the ways users write them, most of them not in canonical form.
+var quantities = []string{"", "0", "500m", "1", "2", "1024Mi", "1Gi", "1.5Gi", "512M", "100Mi", "10G"}
+
+func newConversionFuzzer(seed int64) *fuzz.Fuzzer {
+	return newFuzzer(seed).Funcs(
+		func(r *spec.ResourceSpec, c fuzz.Continue) {
+			pick := func() string { return quantities[c.Intn(len(quantities))] }
+			*r = spec.ResourceSpec{Memory: pick(), DiskSpace: pick(), CPU: pick(), JvmHeap: pick()}
+		},
+	)
+}
+
+func TestConversionRoundTrip(t *testing.T) {
+	for seed := int64(0); seed < fuzzIterations; seed++ {
+		original := &spec.ZookeeperCluster{}
+		newConversionFuzzer(seed).Fuzz(original)
+		original.APIVersion = spec.SchemeGroupVersion.String()
+
+		converted := &v1beta1.ZookeeperCluster{}
+		if err := v1beta1.ConvertFromV1(original, converted); err != nil {
+			t.Fatalf("seed %d: ConvertFromV1: %v", seed, err)
+		}
+		roundTripped := &spec.ZookeeperCluster{}
+		if err := v1beta1.ConvertToV1(converted, roundTripped); err != nil {
+			t.Fatalf("seed %d: ConvertToV1: %v", seed, err)
+		}
+		if !apiequality.Semantic.DeepEqual(original, roundTripped) {
+			t.Fatalf("seed %d: round trip differs from original\noriginal:      %#v\nround tripped: %#v", seed, original, roundTripped)
+		}
+	}
+}
+
+func TestConversionKeepsQuantities(t *testing.T) {
+	original := &spec.ZookeeperCluster{
+		Spec: spec.ZookeeperClusterSpec{
+			BrokerCount: 3,
+			Resources:   spec.ResourceSpec{Memory: "1024Mi", JvmHeap: "512Mi", DiskSpace: "1.5Gi"},
+		},
+	}
+	converted := &v1beta1.ZookeeperCluster{}
+	if err := v1beta1.ConvertFromV1(original, converted); err != nil {
+		t.Fatalf("ConvertFromV1: %v", err)
+	}
+	if got := converted.Spec.Resources.Memory.String(); got != "1Gi" {
+		t.Errorf("v1beta1 memory is %s, want 1Gi", got)
+	}
+
+	// a quantity changed at v1beta1 is stored in canonical form, the others keep their string
+	diskSpace := resource.MustParse("2Gi")
+	converted.Spec.Storage.Size = &diskSpace
+	roundTripped := &spec.ZookeeperCluster{}
+	if err := v1beta1.ConvertToV1(converted, roundTripped); err != nil {
+		t.Fatalf("ConvertToV1: %v", err)
+	}
+	want := spec.ResourceSpec{Memory: "1024Mi", JvmHeap: "512Mi", DiskSpace: "2Gi"}
+	if roundTripped.Spec.Resources != want {
+		t.Errorf("resources are %+v, want %+v", roundTripped.Spec.Resources, want)
+	}
+	if _, ok := roundTripped.Annotations[v1beta1.QuantitiesAnnotation]; ok {
+		t.Errorf("%s is stored at v1", v1beta1.QuantitiesAnnotation)
+	}
+}
@@ -0,0 +1,114 @@
+package spec_test
+
+import (
+	"reflect"
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+	"github.com/liwang-pivotal/zookeeper-operator/spec/v1beta1"
+)
+
+const fuzzIterations = 200
+
+var objects = []runtime.Object{
+	&spec.ZookeeperCluster{},
+	&spec.ZookeeperClusterList{},
+	&spec.ZookeeperUser{},
+	&spec.ZookeeperUserList{},
+	&spec.ZookeeperBackup{},
+	&spec.ZookeeperBackupList{},
+	&v1beta1.ZookeeperCluster{},
+	&v1beta1.ZookeeperClusterList{},
+}
+
+func newFuzzer(seed int64) *fuzz.Fuzzer {
+	return fuzz.NewWithSeed(seed).NilChance(0.2).NumElements(1, 3).Funcs(
+		func(t *metav1.Time, c fuzz.Continue) {
+			*t = metav1.Unix(c.Int63n(1<<32), 0)
+		},
+		func(q *resource.Quantity, c fuzz.Continue) {
+			*q = *resource.NewQuantity(c.Int63n(1<<20), resource.BinarySI)
+		},
+		// managed fields are a trie of unbounded depth, one level is enough to copy
+		func(f *metav1.Fields, c fuzz.Continue) {
+			f.Map = map[string]metav1.Fields{c.RandString(): {}}
+		},
+	)
+}
+
+// fuzzedPair returns two equal, independently fuzzed instances of the type of obj.
+func fuzzedPair(obj runtime.Object, seed int64) (runtime.Object, runtime.Object) {
+	typ := reflect.TypeOf(obj).Elem()
+	a := reflect.New(typ).Interface().(runtime.Object)
+	b := reflect.New(typ).Interface().(runtime.Object)
+	newFuzzer(seed).Fuzz(a)
+	newFuzzer(seed).Fuzz(b)
+	return a, b
+}
+
+func TestDeepCopyRoundTrip(t *testing.T) {
+	for _, obj := range objects {
+		for seed := int64(0); seed < fuzzIterations; seed++ {
+			original, _ := fuzzedPair(obj, seed)
+			copied := original.DeepCopyObject()
+			if !apiequality.Semantic.DeepEqual(original, copied) {
+				t.Fatalf("%T seed %d: copy differs from original\noriginal: %#v\ncopy:     %#v", obj, seed, original, copied)
+			}
+		}
+	}
+}
+
+func TestDeepCopyIndependence(t *testing.T) {
+	for _, obj := range objects {
+		for seed := int64(0); seed < fuzzIterations; seed++ {
+			original, reference := fuzzedPair(obj, seed)
+			copied := original.DeepCopyObject()
+			mutate(reflect.ValueOf(copied))
+			if !apiequality.Semantic.DeepEqual(original, reference) {
+				t.Fatalf("%T seed %d: changing the copy changed the original\noriginal:  %#v\nreference: %#v", obj, seed, original, reference)
+			}
+		}
+	}
+}
+
+// mutate changes every exported value reachable from v in place, so that any memory
+// a copy still shares with its original shows up as a change of the original.
+func mutate(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			mutate(v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				mutate(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			mutate(v.Index(i))
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(v.MapIndex(key))
+			mutate(value)
+			v.SetMapIndex(key, value)
+		}
+	case reflect.String:
+		v.SetString(v.String() + "-mutated")
+	case reflect.Bool:
+		v.SetBool(!v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(v.Int() + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(v.Uint() + 1)
+	}
+}
@@ -0,0 +1,34 @@
+package spec
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	DefaultCPU       = "500m"
+	DefaultDiskSpace = "100Mi"
+	DefaultMemory    = "200Mi"
+
+	DefaultMetricsPort      = 7000
+	DefaultJmxExporterImage = "bitnami/jmx-exporter:0.13.0"
+)
+
+// SetDefaults fills the unset resources of a cluster. The heap defaults to half of the memory,
+// leaving the rest to the JVM and the page cache.
+func SetDefaults(clusterSpec *ZookeeperClusterSpec) {
+	resources := &clusterSpec.Resources
+	if resources.CPU == "" {
+		resources.CPU = DefaultCPU
+	}
+	if resources.DiskSpace == "" {
+		resources.DiskSpace = DefaultDiskSpace
+	}
+	if resources.Memory == "" {
+		resources.Memory = DefaultMemory
+	}
+	if resources.JvmHeap == "" {
+		if memory, err := resource.ParseQuantity(resources.Memory); err == nil {
+			resources.JvmHeap = resource.NewQuantity(memory.Value()/2, resource.BinarySI).String()
+		}
+	}
+}
@@ -0,0 +1,6 @@
+// Package spec is the v1 version of the pivotal.io API, the storage version of all
+// resources managed by the operator.
+//
+// +k8s:deepcopy-gen=package
+// +groupName=pivotal.io
+package spec
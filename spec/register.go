@@ -16,10 +16,15 @@ const (
 	CRDRessourcePlural = "zookeeperclusters"
 	CRDName            = "zookeepercluster"
 	CRDVersion         = "v1"
+
+	CRDUserRessourcePlural   = "zookeeperusers"
+	CRDBackupRessourcePlural = "zookeeperbackups"
 )
 
 var (
-	CRDFullName = CRDRessourcePlural + "." + CRDGroupName
+	CRDFullName       = CRDRessourcePlural + "." + CRDGroupName
+	CRDUserFullName   = CRDUserRessourcePlural + "." + CRDGroupName
+	CRDBackupFullName = CRDBackupRessourcePlural + "." + CRDGroupName
 )
 
 // GroupName is the group name used in this package.
@@ -37,6 +42,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&ZookeeperCluster{},
 		&ZookeeperClusterList{},
+		&ZookeeperUser{},
+		&ZookeeperUserList{},
+		&ZookeeperBackup{},
+		&ZookeeperBackupList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -3,11 +3,15 @@ package spec
 import (
 	"fmt"
 
+	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 type ZookeeperCluster struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata"`
@@ -17,6 +21,8 @@ type ZookeeperCluster struct {
 	Scale ZookeeperClusterScale `json:"scale,omitempty"`
 }
 
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 type ZookeeperClusterList struct {
 	metav1.TypeMeta `json:",inline"`
 	metav1.ListMeta `json:"metadata"`
@@ -25,28 +31,166 @@ type ZookeeperClusterList struct {
 }
 
 type ZookeeperClusterSpec struct {
-	Image            string            `json:"image"`
-	BrokerCount      int32             `json:"brokerCount"`
-	Resources        ResourceSpec      `json:"resources"`
-	StorageClass     string            `json:"storageClass"`
+	Image        string         `json:"image"`
+	BrokerCount  int32          `json:"brokerCount" schema:"required,minimum=1,maximum=9"`
+	Resources    ResourceSpec   `json:"resources"`
+	StorageClass string         `json:"storageClass"`
+	RestoreFrom  *RestoreSource `json:"restoreFrom,omitempty"`
+	// Monitoring exposes the metrics of ZooKeeper to Prometheus.
+	Monitoring *MonitoringSpec `json:"monitoring,omitempty"`
+	// SecurityContext overrides the security settings the members run with.
+	SecurityContext *SecurityContextSpec `json:"securityContext,omitempty"`
+	// Probes configures how the readiness and liveness of the members is checked.
+	Probes *ProbesSpec `json:"probes,omitempty"`
+	// Startup lets the operator render the configuration of the members instead of zkGenConfig.sh.
+	Startup *StartupSpec `json:"startup,omitempty"`
+}
+
+// RestoreSource seeds the data directory of every member from a backup before the ensemble starts.
+type RestoreSource struct {
+	// Backup is the name of a ZookeeperBackup in the same namespace.
+	Backup string `json:"backup" schema:"required,minLength=1"`
+	// Archive defaults to the newest archive of the backup.
+	Archive string `json:"archive,omitempty"`
+	// Force restores into a cluster that already has data, replacing it.
+	Force bool `json:"force,omitempty"`
 }
 
 type ZookeeperClusterState struct {
+	Restore  *RestoreState  `json:"restore,omitempty"`
+	Recovery *RecoveryState `json:"recovery,omitempty"`
+}
+
+const (
+	RestorePhaseSeeding  = "Seeding"
+	RestorePhaseSeeded   = "Seeded"
+	RestorePhaseVerified = "Verified"
+	RestorePhaseFailed   = "Failed"
+	RestorePhaseRefused  = "Refused"
+)
+
+type RestoreState struct {
+	Phase   string `json:"phase"`
+	Backup  string `json:"backup,omitempty"`
+	Archive string `json:"archive,omitempty"`
+	// Zxid is the zxid recorded when the archive was taken.
+	Zxid    string `json:"zxid,omitempty"`
+	Message string `json:"message,omitempty"`
 }
 
 type ZookeeperClusterScale struct {
 }
 
+// RecoverAnnotation triggers the quorum loss recovery of a cluster. Every new value starts a new recovery.
+const RecoverAnnotation = "zookeeper.pivotal.io/recover"
+
+const (
+	RecoveryPhaseRunning   = "Running"
+	RecoveryPhaseCompleted = "Completed"
+	RecoveryPhaseFailed    = "Failed"
+)
+
+type RecoveryState struct {
+	Phase string `json:"phase"`
+	// Trigger is the value of the recover annotation that started the recovery.
+	Trigger string `json:"trigger"`
+	// Survivor is the member whose data the ensemble was rebuilt from.
+	Survivor string         `json:"survivor,omitempty"`
+	Zxid     string         `json:"zxid,omitempty"`
+	Steps    []RecoveryStep `json:"steps,omitempty"`
+}
+
+type RecoveryStep struct {
+	Time    metav1.Time `json:"time"`
+	Step    string      `json:"step"`
+	Message string      `json:"message"`
+}
+
 type ZookeeperClusterWatchEvent struct {
-	Type      string       `json:"type"`
+	Type      string           `json:"type"`
 	Object    ZookeeperCluster `json:"object"`
 	OldObject ZookeeperCluster `json:"oldObject"`
 }
 
 type ResourceSpec struct {
-	Memory    string `json:"memory"`
-	DiskSpace string `json:"diskSpace"`
-	CPU       string `json:"cpu"`
+	Memory    string `json:"memory,omitempty" schema:"format=quantity"`
+	DiskSpace string `json:"diskSpace,omitempty" schema:"format=quantity"`
+	CPU       string `json:"cpu,omitempty" schema:"format=quantity"`
+	// JvmHeap is the maximum heap of ZooKeeper, it must fit into Memory.
+	JvmHeap string `json:"jvmHeap,omitempty" schema:"format=quantity"`
+}
+
+const (
+	// MonitoringExporterJMX runs the Prometheus JMX exporter as a sidecar of every member.
+	MonitoringExporterJMX = "jmx"
+	// MonitoringExporterNative enables the Prometheus metrics provider of ZooKeeper 3.6+.
+	MonitoringExporterNative = "native"
+)
+
+type MonitoringSpec struct {
+	Exporter string `json:"exporter" schema:"required,enum=jmx|native"`
+	// Port the metrics are served on by every member, defaults to 7000.
+	Port int32 `json:"port,omitempty" schema:"minimum=1,maximum=65535"`
+	// Image of the JMX exporter sidecar.
+	Image string `json:"image,omitempty"`
+	// ServiceMonitor configures the ServiceMonitor generated when the Prometheus Operator is installed.
+	ServiceMonitor *ServiceMonitorSpec `json:"serviceMonitor,omitempty"`
+}
+
+type ServiceMonitorSpec struct {
+	// Labels of the ServiceMonitor, to be matched by the serviceMonitorSelector of a Prometheus.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Interval at which Prometheus scrapes the members, the Prometheus default if unset.
+	Interval string `json:"interval,omitempty" schema:"pattern=^([0-9]+(ms|s|m|h))+$"`
+}
+
+// SecurityContextSpec overrides single fields of the default security contexts, the unset fields
+// keep their defaults.
+type SecurityContextSpec struct {
+	// Pod is the security context of the pods, it defaults to the zookeeper user and group 1000.
+	Pod *v1.PodSecurityContext `json:"pod,omitempty"`
+	// Container is the security context of every container, it defaults to a non-privileged
+	// container without capabilities and with a read-only root filesystem.
+	Container *v1.SecurityContext `json:"container,omitempty"`
+}
+
+const (
+	// ProbeModeScript runs zkOk.sh, which only the k8szk image provides.
+	ProbeModeScript = "script"
+	// ProbeModeZkutil runs zkutil probe, copied into the pods from the probes image.
+	ProbeModeZkutil = "zkutil"
+	// ProbeModeTCP checks that the client port accepts connections.
+	ProbeModeTCP = "tcp"
+)
+
+type ProbesSpec struct {
+	// Mode is script, zkutil or tcp, it defaults to script.
+	Mode string `json:"mode,omitempty" schema:"enum=script|zkutil|tcp"`
+	// Image providing zkutil on its PATH, required by the zkutil mode.
+	Image     string        `json:"image,omitempty"`
+	Readiness *ProbeTimings `json:"readiness,omitempty"`
+	Liveness  *ProbeTimings `json:"liveness,omitempty"`
+}
+
+// ProbeTimings of a probe, unset fields keep their defaults.
+type ProbeTimings struct {
+	// InitialDelaySeconds defaults to 10.
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty" schema:"minimum=0"`
+	// TimeoutSeconds defaults to 5.
+	TimeoutSeconds   int32 `json:"timeoutSeconds,omitempty" schema:"minimum=1"`
+	PeriodSeconds    int32 `json:"periodSeconds,omitempty" schema:"minimum=1"`
+	FailureThreshold int32 `json:"failureThreshold,omitempty" schema:"minimum=1"`
+}
+
+// StartupSpec runs zkutil init before every member starts. It waits until the DNS name of the
+// member resolves and writes myid and the configuration, it doesn't rely on the order members start in.
+type StartupSpec struct {
+	// Image providing zkutil on its PATH.
+	Image string `json:"image" schema:"required,minLength=1"`
+	// Timeout of the wait for the DNS name, it defaults to 5m.
+	Timeout string `json:"timeout,omitempty" schema:"pattern=^([0-9]+(ms|s|m|h))+$"`
+	// DynamicConfig writes the members into a dynamic configuration file, which needs ZooKeeper 3.5+.
+	DynamicConfig bool `json:"dynamicConfig,omitempty"`
 }
 
 func PrintCluster(cluster *ZookeeperCluster) string {
@@ -63,67 +207,3 @@ func (el *ZookeeperClusterList) GetObjectKind() schema.ObjectKind {
 
 	return &el.TypeMeta
 }
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ZookeeperCluster) DeepCopyInto(out *ZookeeperCluster) {
-	*out = *in
-	out.TypeMeta = in.TypeMeta
-	//in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.ObjectMeta = in.ObjectMeta
-
-	out.Spec = in.Spec
-	return
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, creating a new Kafkacluster.
-func (x *ZookeeperCluster) DeepCopy() *ZookeeperCluster {
-	if x == nil {
-		return nil
-	}
-	out := new(ZookeeperCluster)
-	x.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (x *ZookeeperCluster) DeepCopyObject() runtime.Object {
-	if c := x.DeepCopy(); c != nil {
-		return c
-	} else {
-		return nil
-	}
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ZookeeperClusterList) DeepCopyInto(out *ZookeeperClusterList) {
-	*out = *in
-	out.TypeMeta = in.TypeMeta
-	out.ListMeta = in.ListMeta
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]ZookeeperCluster, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	return
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, creating a new KafkaclusterList.
-func (x *ZookeeperClusterList) DeepCopy() *ZookeeperClusterList {
-	if x == nil {
-		return nil
-	}
-	out := new(ZookeeperClusterList)
-	x.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (x *ZookeeperClusterList) DeepCopyObject() runtime.Object {
-	if c := x.DeepCopy(); c != nil {
-		return c
-	} else {
-		return nil
-	}
-}
\ No newline at end of file
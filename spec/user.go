@@ -0,0 +1,93 @@
+package spec
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ZookeeperUser is an application identity on a ZookeeperCluster. The operator
+// generates its credentials into a Secret and grants it ACLs on the listed znodes.
+type ZookeeperUser struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec  ZookeeperUserSpec  `json:"spec"`
+	State ZookeeperUserState `json:"state,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ZookeeperUserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ZookeeperUser `json:"items"`
+}
+
+type ZookeeperUserSpec struct {
+	// ClusterName is the name of the ZookeeperCluster in the same namespace.
+	ClusterName string `json:"clusterName" schema:"required,minLength=1"`
+	// Username defaults to the name of the ZookeeperUser.
+	Username string `json:"username,omitempty"`
+	// SecretName defaults to <name>-zk-credentials.
+	SecretName string     `json:"secretName,omitempty"`
+	ACLs       []ZnodeACL `json:"acls,omitempty"`
+}
+
+// ZnodeACL grants permissions (read, write, create, delete, admin or all) on a single znode.
+type ZnodeACL struct {
+	Path        string   `json:"path" schema:"required,pattern=^/"`
+	Permissions []string `json:"permissions" schema:"required,enum=read|write|create|delete|admin|all"`
+}
+
+type ZookeeperUserState struct {
+	SecretName string     `json:"secretName,omitempty"`
+	Grants     []ZnodeACL `json:"grants,omitempty"`
+	// Digest is the identity the grants belong to. It is kept here because the garbage
+	// collector may delete the Secret before the grants are revoked.
+	Digest  string `json:"digest,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// UserFinalizer keeps a ZookeeperUser until the operator revoked its grants.
+const UserFinalizer = "zookeeper.pivotal.io/revoke-acls"
+
+type ZookeeperUserWatchEvent struct {
+	Type      string        `json:"type"`
+	Object    ZookeeperUser `json:"object"`
+	OldObject ZookeeperUser `json:"oldObject"`
+}
+
+func PrintUser(user *ZookeeperUser) string {
+	return fmt.Sprintf("%s/%s, APIVersion: %s, Kind: %s, Value: %#v", user.ObjectMeta.Namespace, user.ObjectMeta.Name, user.APIVersion, user.Kind, user)
+}
+
+func (u *ZookeeperUser) GetUsername() string {
+	if u.Spec.Username != "" {
+		return u.Spec.Username
+	}
+	return u.ObjectMeta.Name
+}
+
+func (u *ZookeeperUser) GetSecretName() string {
+	if u.Spec.SecretName != "" {
+		return u.Spec.SecretName
+	}
+	return u.ObjectMeta.Name + "-zk-credentials"
+}
+
+// Required to satisfy Object interface
+func (u *ZookeeperUser) GetObjectKind() schema.ObjectKind {
+	return &u.TypeMeta
+}
+
+// Required to satisfy Object interface
+func (ul *ZookeeperUserList) GetObjectKind() schema.ObjectKind {
+	return &ul.TypeMeta
+}
@@ -0,0 +1,270 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/liwang-pivotal/zookeeper-operator/spec"
+)
+
+// QuantitiesAnnotation keeps the resource strings of a v1 cluster on its v1beta1 version. A
+// resource.Quantity only keeps the value, so 1024Mi would otherwise be stored as 1Gi again.
+const QuantitiesAnnotation = "zookeeper.pivotal.io/v1-quantities"
+
+// ConvertFromV1 converts a v1 cluster into this version.
+func ConvertFromV1(in *spec.ZookeeperCluster, out *ZookeeperCluster) error {
+	var err error
+	out.TypeMeta = in.TypeMeta
+	out.APIVersion = SchemeGroupVersion.String()
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+
+	out.Spec = ZookeeperClusterSpec{
+		Replicas: in.Spec.BrokerCount,
+		Image:    in.Spec.Image,
+		Storage: Storage{
+			StorageClassName: in.Spec.StorageClass,
+		},
+	}
+	resources := in.Spec.Resources
+	if out.Spec.Resources.CPU, err = parseQuantity("resources.cpu", resources.CPU); err != nil {
+		return err
+	}
+	if out.Spec.Resources.Memory, err = parseQuantity("resources.memory", resources.Memory); err != nil {
+		return err
+	}
+	if out.Spec.Resources.JvmHeap, err = parseQuantity("resources.jvmHeap", resources.JvmHeap); err != nil {
+		return err
+	}
+	if out.Spec.Storage.Size, err = parseQuantity("resources.diskSpace", resources.DiskSpace); err != nil {
+		return err
+	}
+	if resources.CPU != formatQuantity(out.Spec.Resources.CPU) ||
+		resources.Memory != formatQuantity(out.Spec.Resources.Memory) ||
+		resources.JvmHeap != formatQuantity(out.Spec.Resources.JvmHeap) ||
+		resources.DiskSpace != formatQuantity(out.Spec.Storage.Size) {
+		original, err := json.Marshal(resources)
+		if err != nil {
+			return err
+		}
+		if out.Annotations == nil {
+			out.Annotations = map[string]string{}
+		}
+		out.Annotations[QuantitiesAnnotation] = string(original)
+	}
+	if in.Spec.RestoreFrom != nil {
+		out.Spec.RestoreFrom = &RestoreSource{
+			Backup:  in.Spec.RestoreFrom.Backup,
+			Archive: in.Spec.RestoreFrom.Archive,
+			Force:   in.Spec.RestoreFrom.Force,
+		}
+	}
+	if monitoring := in.Spec.Monitoring; monitoring != nil {
+		out.Spec.Monitoring = &Monitoring{
+			Exporter: monitoring.Exporter,
+			Port:     monitoring.Port,
+			Image:    monitoring.Image,
+		}
+		if serviceMonitor := monitoring.ServiceMonitor; serviceMonitor != nil {
+			out.Spec.Monitoring.ServiceMonitor = &ServiceMonitor{
+				Labels:   copyLabels(serviceMonitor.Labels),
+				Interval: serviceMonitor.Interval,
+			}
+		}
+	}
+	if securityContext := in.Spec.SecurityContext; securityContext != nil {
+		out.Spec.SecurityContext = &SecurityContext{
+			Pod:       securityContext.Pod.DeepCopy(),
+			Container: securityContext.Container.DeepCopy(),
+		}
+	}
+	if probes := in.Spec.Probes; probes != nil {
+		out.Spec.Probes = &Probes{
+			Mode:  probes.Mode,
+			Image: probes.Image,
+		}
+		if probes.Readiness != nil {
+			readiness := ProbeTimings(*probes.Readiness)
+			out.Spec.Probes.Readiness = &readiness
+		}
+		if probes.Liveness != nil {
+			liveness := ProbeTimings(*probes.Liveness)
+			out.Spec.Probes.Liveness = &liveness
+		}
+	}
+	if in.Spec.Startup != nil {
+		startup := Startup(*in.Spec.Startup)
+		out.Spec.Startup = &startup
+	}
+
+	out.Status = ZookeeperClusterStatus{}
+	if restore := in.State.Restore; restore != nil {
+		out.Status.Restore = &RestoreStatus{
+			Phase:   restore.Phase,
+			Backup:  restore.Backup,
+			Archive: restore.Archive,
+			Zxid:    restore.Zxid,
+			Message: restore.Message,
+		}
+	}
+	if recovery := in.State.Recovery; recovery != nil {
+		out.Status.Recovery = &RecoveryStatus{
+			Phase:    recovery.Phase,
+			Trigger:  recovery.Trigger,
+			Survivor: recovery.Survivor,
+			Zxid:     recovery.Zxid,
+		}
+		for _, step := range recovery.Steps {
+			out.Status.Recovery.Steps = append(out.Status.Recovery.Steps, RecoveryStep{
+				Time:    *step.Time.DeepCopy(),
+				Step:    step.Step,
+				Message: step.Message,
+			})
+		}
+	}
+	return nil
+}
+
+// ConvertToV1 converts a cluster of this version into v1, the storage version.
+func ConvertToV1(in *ZookeeperCluster, out *spec.ZookeeperCluster) error {
+	out.TypeMeta = in.TypeMeta
+	out.APIVersion = spec.SchemeGroupVersion.String()
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+
+	// quantities that weren't changed at this version keep their v1 string
+	var original spec.ResourceSpec
+	if annotation, ok := out.Annotations[QuantitiesAnnotation]; ok {
+		if err := json.Unmarshal([]byte(annotation), &original); err != nil {
+			return fmt.Errorf("annotation %s: %v", QuantitiesAnnotation, err)
+		}
+		delete(out.Annotations, QuantitiesAnnotation)
+		if len(out.Annotations) == 0 {
+			out.Annotations = nil
+		}
+	}
+
+	out.Spec = spec.ZookeeperClusterSpec{
+		Image:        in.Spec.Image,
+		BrokerCount:  in.Spec.Replicas,
+		StorageClass: in.Spec.Storage.StorageClassName,
+		Resources: spec.ResourceSpec{
+			CPU:       formatQuantityAs(in.Spec.Resources.CPU, original.CPU),
+			Memory:    formatQuantityAs(in.Spec.Resources.Memory, original.Memory),
+			JvmHeap:   formatQuantityAs(in.Spec.Resources.JvmHeap, original.JvmHeap),
+			DiskSpace: formatQuantityAs(in.Spec.Storage.Size, original.DiskSpace),
+		},
+	}
+	if in.Spec.RestoreFrom != nil {
+		out.Spec.RestoreFrom = &spec.RestoreSource{
+			Backup:  in.Spec.RestoreFrom.Backup,
+			Archive: in.Spec.RestoreFrom.Archive,
+			Force:   in.Spec.RestoreFrom.Force,
+		}
+	}
+	if monitoring := in.Spec.Monitoring; monitoring != nil {
+		out.Spec.Monitoring = &spec.MonitoringSpec{
+			Exporter: monitoring.Exporter,
+			Port:     monitoring.Port,
+			Image:    monitoring.Image,
+		}
+		if serviceMonitor := monitoring.ServiceMonitor; serviceMonitor != nil {
+			out.Spec.Monitoring.ServiceMonitor = &spec.ServiceMonitorSpec{
+				Labels:   copyLabels(serviceMonitor.Labels),
+				Interval: serviceMonitor.Interval,
+			}
+		}
+	}
+	if securityContext := in.Spec.SecurityContext; securityContext != nil {
+		out.Spec.SecurityContext = &spec.SecurityContextSpec{
+			Pod:       securityContext.Pod.DeepCopy(),
+			Container: securityContext.Container.DeepCopy(),
+		}
+	}
+	if probes := in.Spec.Probes; probes != nil {
+		out.Spec.Probes = &spec.ProbesSpec{
+			Mode:  probes.Mode,
+			Image: probes.Image,
+		}
+		if probes.Readiness != nil {
+			readiness := spec.ProbeTimings(*probes.Readiness)
+			out.Spec.Probes.Readiness = &readiness
+		}
+		if probes.Liveness != nil {
+			liveness := spec.ProbeTimings(*probes.Liveness)
+			out.Spec.Probes.Liveness = &liveness
+		}
+	}
+	if in.Spec.Startup != nil {
+		startup := spec.StartupSpec(*in.Spec.Startup)
+		out.Spec.Startup = &startup
+	}
+
+	out.State = spec.ZookeeperClusterState{}
+	if restore := in.Status.Restore; restore != nil {
+		out.State.Restore = &spec.RestoreState{
+			Phase:   restore.Phase,
+			Backup:  restore.Backup,
+			Archive: restore.Archive,
+			Zxid:    restore.Zxid,
+			Message: restore.Message,
+		}
+	}
+	if recovery := in.Status.Recovery; recovery != nil {
+		out.State.Recovery = &spec.RecoveryState{
+			Phase:    recovery.Phase,
+			Trigger:  recovery.Trigger,
+			Survivor: recovery.Survivor,
+			Zxid:     recovery.Zxid,
+		}
+		for _, step := range recovery.Steps {
+			out.State.Recovery.Steps = append(out.State.Recovery.Steps, spec.RecoveryStep{
+				Time:    *step.Time.DeepCopy(),
+				Step:    step.Step,
+				Message: step.Message,
+			})
+		}
+	}
+	return nil
+}
+
+// parseQuantity converts an unset v1 quantity into nil.
+func parseQuantity(field, value string) (*resource.Quantity, error) {
+	if value == "" {
+		return nil, nil
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", field, err)
+	}
+	return &quantity, nil
+}
+
+func formatQuantity(quantity *resource.Quantity) string {
+	if quantity == nil {
+		return ""
+	}
+	return quantity.String()
+}
+
+// formatQuantityAs returns original if it is the same quantity, otherwise the canonical form.
+func formatQuantityAs(quantity *resource.Quantity, original string) string {
+	if quantity == nil || original == "" {
+		return formatQuantity(quantity)
+	}
+	if parsed, err := resource.ParseQuantity(original); err == nil && parsed.Cmp(*quantity) == 0 {
+		return original
+	}
+	return quantity.String()
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	out := make(map[string]string, len(labels))
+	for key, value := range labels {
+		out[key] = value
+	}
+	return out
+}
@@ -0,0 +1,6 @@
+// Package v1beta1 is the v1beta1 version of the pivotal.io API. It replaces the Kafka
+// derived naming of v1 and is served from v1 objects through the conversion webhook.
+//
+// +k8s:deepcopy-gen=package
+// +groupName=pivotal.io
+package v1beta1
@@ -0,0 +1,150 @@
+package v1beta1
+
+import (
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ZookeeperCluster is a ZooKeeper ensemble run as a StatefulSet.
+type ZookeeperCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ZookeeperClusterSpec   `json:"spec"`
+	Status ZookeeperClusterStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ZookeeperClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ZookeeperCluster `json:"items"`
+}
+
+type ZookeeperClusterSpec struct {
+	// Replicas is the number of ensemble members.
+	Replicas int32 `json:"replicas" schema:"required,minimum=1,maximum=9"`
+	// Image of the ZooKeeper containers.
+	Image     string    `json:"image,omitempty"`
+	Resources Resources `json:"resources,omitempty"`
+	Storage   Storage   `json:"storage,omitempty"`
+	// RestoreFrom seeds the data directory of every member from a backup before the ensemble starts.
+	RestoreFrom *RestoreSource `json:"restoreFrom,omitempty"`
+	// Monitoring exposes the metrics of ZooKeeper to Prometheus.
+	Monitoring *Monitoring `json:"monitoring,omitempty"`
+	// SecurityContext overrides the security settings the members run with.
+	SecurityContext *SecurityContext `json:"securityContext,omitempty"`
+	// Probes configures how the readiness and liveness of the members is checked.
+	Probes *Probes `json:"probes,omitempty"`
+	// Startup lets the operator render the configuration of the members instead of zkGenConfig.sh.
+	Startup *Startup `json:"startup,omitempty"`
+}
+
+// Resources of every member. Unset quantities are defaulted by the operator.
+type Resources struct {
+	CPU    *resource.Quantity `json:"cpu,omitempty"`
+	Memory *resource.Quantity `json:"memory,omitempty"`
+	// JvmHeap is the maximum heap of ZooKeeper, it must fit into Memory.
+	JvmHeap *resource.Quantity `json:"jvmHeap,omitempty"`
+}
+
+// Storage describes the data volume of every member.
+type Storage struct {
+	Size             *resource.Quantity `json:"size,omitempty"`
+	StorageClassName string             `json:"storageClassName,omitempty"`
+}
+
+type RestoreSource struct {
+	// Backup is the name of a ZookeeperBackup in the same namespace.
+	Backup string `json:"backup" schema:"required,minLength=1"`
+	// Archive defaults to the newest archive of the backup.
+	Archive string `json:"archive,omitempty"`
+	// Force restores into a cluster that already has data, replacing it.
+	Force bool `json:"force,omitempty"`
+}
+
+// Monitoring exposes the metrics of ZooKeeper to Prometheus.
+type Monitoring struct {
+	// Exporter is jmx for a JMX exporter sidecar or native for the metrics provider of ZooKeeper 3.6+.
+	Exporter string `json:"exporter" schema:"required,enum=jmx|native"`
+	// Port the metrics are served on by every member, defaults to 7000.
+	Port int32 `json:"port,omitempty" schema:"minimum=1,maximum=65535"`
+	// Image of the JMX exporter sidecar.
+	Image          string          `json:"image,omitempty"`
+	ServiceMonitor *ServiceMonitor `json:"serviceMonitor,omitempty"`
+}
+
+// ServiceMonitor configures the ServiceMonitor generated when the Prometheus Operator is installed.
+type ServiceMonitor struct {
+	// Labels of the ServiceMonitor, to be matched by the serviceMonitorSelector of a Prometheus.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Interval at which Prometheus scrapes the members, the Prometheus default if unset.
+	Interval string `json:"interval,omitempty" schema:"pattern=^([0-9]+(ms|s|m|h))+$"`
+}
+
+// SecurityContext overrides single fields of the default security contexts of the members.
+type SecurityContext struct {
+	Pod       *v1.PodSecurityContext `json:"pod,omitempty"`
+	Container *v1.SecurityContext    `json:"container,omitempty"`
+}
+
+type Probes struct {
+	// Mode is script, zkutil or tcp, it defaults to script.
+	Mode string `json:"mode,omitempty" schema:"enum=script|zkutil|tcp"`
+	// Image providing zkutil on its PATH, required by the zkutil mode.
+	Image     string        `json:"image,omitempty"`
+	Readiness *ProbeTimings `json:"readiness,omitempty"`
+	Liveness  *ProbeTimings `json:"liveness,omitempty"`
+}
+
+// ProbeTimings of a probe, unset fields keep their defaults.
+type ProbeTimings struct {
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty" schema:"minimum=0"`
+	TimeoutSeconds      int32 `json:"timeoutSeconds,omitempty" schema:"minimum=1"`
+	PeriodSeconds       int32 `json:"periodSeconds,omitempty" schema:"minimum=1"`
+	FailureThreshold    int32 `json:"failureThreshold,omitempty" schema:"minimum=1"`
+}
+
+// Startup runs zkutil init before every member starts.
+type Startup struct {
+	// Image providing zkutil on its PATH.
+	Image string `json:"image" schema:"required,minLength=1"`
+	// Timeout of the wait for the DNS name, it defaults to 5m.
+	Timeout string `json:"timeout,omitempty" schema:"pattern=^([0-9]+(ms|s|m|h))+$"`
+	// DynamicConfig writes the members into a dynamic configuration file, which needs ZooKeeper 3.5+.
+	DynamicConfig bool `json:"dynamicConfig,omitempty"`
+}
+
+type ZookeeperClusterStatus struct {
+	Restore  *RestoreStatus  `json:"restore,omitempty"`
+	Recovery *RecoveryStatus `json:"recovery,omitempty"`
+}
+
+type RestoreStatus struct {
+	Phase   string `json:"phase"`
+	Backup  string `json:"backup,omitempty"`
+	Archive string `json:"archive,omitempty"`
+	Zxid    string `json:"zxid,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type RecoveryStatus struct {
+	Phase string `json:"phase"`
+	// Trigger is the value of the recover annotation that started the recovery.
+	Trigger string `json:"trigger"`
+	// Survivor is the member whose data the ensemble was rebuilt from.
+	Survivor string         `json:"survivor,omitempty"`
+	Zxid     string         `json:"zxid,omitempty"`
+	Steps    []RecoveryStep `json:"steps,omitempty"`
+}
+
+type RecoveryStep struct {
+	Time    metav1.Time `json:"time"`
+	Step    string      `json:"step"`
+	Message string      `json:"message"`
+}